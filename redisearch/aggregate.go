@@ -0,0 +1,263 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// Reducer is a single REDUCE function applied to each group of a GROUPBY step.
+// See https://oss.redislabs.com/redisearch/Aggregations.html#supported_groupby_reducers
+type Reducer struct {
+	Name  string
+	Args  []interface{}
+	Alias string
+}
+
+// As sets the name under which the reducer's result is returned
+func (r Reducer) As(alias string) Reducer {
+	r.Alias = alias
+	return r
+}
+
+func (r Reducer) serialize() redis.Args {
+	args := redis.Args{"REDUCE", r.Name, len(r.Args)}
+	args = append(args, r.Args...)
+	if r.Alias != "" {
+		args = args.Add("AS", r.Alias)
+	}
+	return args
+}
+
+// fieldRef returns the property reference for a field name, adding the @ prefix if missing
+func fieldRef(name string) string {
+	if strings.HasPrefix(name, "@") {
+		return name
+	}
+	return "@" + name
+}
+
+// ReduceCount counts the records in each group
+func ReduceCount() Reducer {
+	return Reducer{Name: "COUNT"}
+}
+
+// ReduceCountDistinct counts the distinct values of the field in each group
+func ReduceCountDistinct(field string) Reducer {
+	return Reducer{Name: "COUNT_DISTINCT", Args: []interface{}{fieldRef(field)}}
+}
+
+// ReduceSum sums the values of the field in each group
+func ReduceSum(field string) Reducer {
+	return Reducer{Name: "SUM", Args: []interface{}{fieldRef(field)}}
+}
+
+// ReduceAvg averages the values of the field in each group
+func ReduceAvg(field string) Reducer {
+	return Reducer{Name: "AVG", Args: []interface{}{fieldRef(field)}}
+}
+
+// ReduceMin returns the minimal value of the field in each group
+func ReduceMin(field string) Reducer {
+	return Reducer{Name: "MIN", Args: []interface{}{fieldRef(field)}}
+}
+
+// ReduceMax returns the maximal value of the field in each group
+func ReduceMax(field string) Reducer {
+	return Reducer{Name: "MAX", Args: []interface{}{fieldRef(field)}}
+}
+
+// ReduceToList returns all the distinct values of the field in each group as a list
+func ReduceToList(field string) Reducer {
+	return Reducer{Name: "TOLIST", Args: []interface{}{fieldRef(field)}}
+}
+
+// ReduceFirstValue returns the first value of the field in each group
+func ReduceFirstValue(field string) Reducer {
+	return Reducer{Name: "FIRST_VALUE", Args: []interface{}{fieldRef(field)}}
+}
+
+// ReduceFirstValueBy returns the value of the field in the first record of each group,
+// when the group is sorted by the given key
+func ReduceFirstValueBy(field string, by SortingKey) Reducer {
+	order := "DESC"
+	if by.Ascending {
+		order = "ASC"
+	}
+	return Reducer{Name: "FIRST_VALUE", Args: []interface{}{fieldRef(field), "BY", fieldRef(by.Field), order}}
+}
+
+// ReduceQuantile returns the value of the field at the given quantile (0-1) of each group
+func ReduceQuantile(field string, quantile float64) Reducer {
+	return Reducer{Name: "QUANTILE", Args: []interface{}{fieldRef(field), quantile}}
+}
+
+// ReduceStdDev returns the standard deviation of the field in each group
+func ReduceStdDev(field string) Reducer {
+	return Reducer{Name: "STDDEV", Args: []interface{}{fieldRef(field)}}
+}
+
+// AggregateQuery is an FT.AGGREGATE request: a query selecting the documents, followed by
+// a pipeline of steps that are executed in the order they were added.
+// See https://oss.redislabs.com/redisearch/Aggregations.html
+type AggregateQuery struct {
+	Query *Query
+	steps redis.Args
+}
+
+// NewAggregateQuery creates a new aggregation pipeline over the documents matching q.
+// Only the query text and the verbatim flag of q are used
+func NewAggregateQuery(q *Query) *AggregateQuery {
+	return &AggregateQuery{Query: q}
+}
+
+// Load loads the given document fields into the pipeline
+func (a *AggregateQuery) Load(fields ...string) *AggregateQuery {
+	a.steps = a.steps.Add("LOAD", len(fields))
+	for _, f := range fields {
+		a.steps = append(a.steps, fieldRef(f))
+	}
+	return a
+}
+
+// GroupBy groups the records by the given fields, and applies the reducers to each group
+func (a *AggregateQuery) GroupBy(fields []string, reducers ...Reducer) *AggregateQuery {
+	a.steps = a.steps.Add("GROUPBY", len(fields))
+	for _, f := range fields {
+		a.steps = append(a.steps, fieldRef(f))
+	}
+	for _, r := range reducers {
+		a.steps = append(a.steps, r.serialize()...)
+	}
+	return a
+}
+
+// Apply evaluates the expression on each record and stores the result in the given property
+func (a *AggregateQuery) Apply(expr, as string) *AggregateQuery {
+	a.steps = a.steps.Add("APPLY", expr, "AS", as)
+	return a
+}
+
+// Filter drops the records for which the expression evaluates to false
+func (a *AggregateQuery) Filter(expr string) *AggregateQuery {
+	a.steps = a.steps.Add("FILTER", expr)
+	return a
+}
+
+// SortBy sorts the records by the given keys. If max is greater than zero,
+// only the top max records are kept
+func (a *AggregateQuery) SortBy(max int, keys ...SortingKey) *AggregateQuery {
+	a.steps = a.steps.Add("SORTBY", 2*len(keys))
+	for _, k := range keys {
+		if k.Ascending {
+			a.steps = a.steps.Add(fieldRef(k.Field), "ASC")
+		} else {
+			a.steps = a.steps.Add(fieldRef(k.Field), "DESC")
+		}
+	}
+	if max > 0 {
+		a.steps = a.steps.Add("MAX", max)
+	}
+	return a
+}
+
+// Limit keeps num records starting at offset
+func (a *AggregateQuery) Limit(offset, num int) *AggregateQuery {
+	a.steps = a.steps.Add("LIMIT", offset, num)
+	return a
+}
+
+func (a AggregateQuery) serialize() redis.Args {
+	raw := "*"
+	verbatim := false
+	if a.Query != nil {
+		if t := a.Query.text(); t != "" {
+			raw = t
+		}
+		verbatim = a.Query.Flags&QueryVerbatim != 0
+	}
+	args := redis.Args{raw}
+	if verbatim {
+		args = args.Add("VERBATIM")
+	}
+	return append(args, a.steps...)
+}
+
+// AggregateRow is a single record returned from an aggregation, keyed by property name.
+// Values are strings, or lists of values for reducers such as TOLIST
+type AggregateRow map[string]interface{}
+
+// convertValue converts a redis reply value to a string, or a list of converted values
+func convertValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case []byte:
+		return string(val)
+	case []interface{}:
+		ret := make([]interface{}, len(val))
+		for i, e := range val {
+			ret[i] = convertValue(e)
+		}
+		return ret
+	default:
+		return val
+	}
+}
+
+// loadAggregateRow converts a flat key/value reply into a row
+func loadAggregateRow(v interface{}) (AggregateRow, error) {
+	arr, ok := v.([]interface{})
+	if !ok || len(arr)%2 != 0 {
+		return nil, fmt.Errorf("Invalid aggregate row: %v", v)
+	}
+	row := make(AggregateRow, len(arr)/2)
+	for i := 0; i < len(arr); i += 2 {
+		key, err := redis.String(arr[i], nil)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid aggregate row key: %s", err)
+		}
+		row[key] = convertValue(arr[i+1])
+	}
+	return row, nil
+}
+
+// Aggregate runs the aggregation pipeline on the index, and returns the resulting rows
+// along with the total number of records reported by the engine
+func (i *Client) Aggregate(q *AggregateQuery) (rows []AggregateRow, total int, err error) {
+	return i.AggregateContext(context.Background(), q)
+}
+
+// AggregateContext is like Aggregate, but honors the context's cancellation and deadline
+func (i *Client) AggregateContext(ctx context.Context, q *AggregateQuery) (rows []AggregateRow, total int, err error) {
+	conn, err := getConn(ctx, i.pool)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	args := redis.Args{i.name}
+	args = append(args, q.serialize()...)
+
+	res, err := redis.Values(conn.Do("FT.AGGREGATE", args...))
+	if err != nil {
+		return
+	}
+	if len(res) == 0 {
+		return nil, 0, errors.New("Empty aggregate reply")
+	}
+	if total, err = redis.Int(res[0], nil); err != nil {
+		return
+	}
+
+	rows = make([]AggregateRow, 0, len(res)-1)
+	for _, r := range res[1:] {
+		row, e := loadAggregateRow(r)
+		if e != nil {
+			return nil, 0, e
+		}
+		rows = append(rows, row)
+	}
+	return
+}
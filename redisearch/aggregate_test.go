@@ -0,0 +1,134 @@
+package redisearch
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+func TestAggregateQuerySerialize(t *testing.T) {
+	q := NewAggregateQuery(NewQuery("hello").SetFlags(QueryVerbatim)).
+		Load("title").
+		GroupBy([]string{"@user", "country"},
+			ReduceCount().As("num"),
+			ReduceQuantile("price", 0.5).As("median"),
+			ReduceFirstValueBy("title", SortingKey{Field: "date"})).
+		Apply("upper(@title)", "t").
+		Filter("@num > 1").
+		SortBy(10, SortingKey{Field: "num"}, SortingKey{Field: "user", Ascending: true}).
+		Limit(0, 5)
+
+	want := redis.Args{"hello", "VERBATIM",
+		"LOAD", 1, "@title",
+		"GROUPBY", 2, "@user", "@country",
+		"REDUCE", "COUNT", 0, "AS", "num",
+		"REDUCE", "QUANTILE", 2, "@price", 0.5, "AS", "median",
+		"REDUCE", "FIRST_VALUE", 4, "@title", "BY", "@date", "DESC",
+		"APPLY", "upper(@title)", "AS", "t",
+		"FILTER", "@num > 1",
+		"SORTBY", 4, "@num", "DESC", "@user", "ASC", "MAX", 10,
+		"LIMIT", 0, 5,
+	}
+	if got := q.serialize(); !reflect.DeepEqual(got, want) {
+		t.Errorf("serialize() = %v, want %v", got, want)
+	}
+
+	if got := NewAggregateQuery(NewQuery("")).serialize(); !reflect.DeepEqual(got, redis.Args{"*"}) {
+		t.Errorf("serialize() = %v, want [*]", got)
+	}
+}
+
+func TestLoadAggregateRow(t *testing.T) {
+	row, err := loadAggregateRow([]interface{}{
+		[]byte("user"), []byte("foo"),
+		[]byte("titles"), []interface{}{[]byte("a"), []byte("b")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := AggregateRow{"user": "foo", "titles": []interface{}{"a", "b"}}
+	if !reflect.DeepEqual(row, want) {
+		t.Errorf("loadAggregateRow() = %v, want %v", row, want)
+	}
+
+	if _, err = loadAggregateRow([]interface{}{[]byte("user")}); err == nil {
+		t.Error("expected an error for an odd row")
+	}
+}
+
+func TestAggregateCursorLoad(t *testing.T) {
+	c := &AggregateCursor{}
+	err := c.load([]interface{}{
+		[]interface{}{int64(3), []interface{}{[]byte("user"), []byte("foo")}},
+		int64(42),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.id != 42 || c.Total() != 3 || len(c.Rows()) != 1 || c.Rows()[0]["user"] != "foo" {
+		t.Errorf("load() = id %d, total %d, rows %v", c.id, c.Total(), c.Rows())
+	}
+
+	if err = c.load([]interface{}{int64(0)}); err == nil {
+		t.Error("expected an error for a malformed reply")
+	}
+}
+
+// cursorPool hands out connections whose FT.CURSOR READ fails with a network error, breaking them
+type cursorPool struct {
+	conns    int
+	commands []string
+}
+
+func (p *cursorPool) Get() redis.Conn {
+	p.conns++
+	return &cursorConn{pool: p, n: p.conns}
+}
+func (p *cursorPool) Close() error { return nil }
+
+type cursorConn struct {
+	redis.Conn
+	pool *cursorPool
+	n    int
+	err  error
+}
+
+func (c *cursorConn) Close() error { return nil }
+func (c *cursorConn) Err() error   { return c.err }
+func (c *cursorConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	c.pool.commands = append(c.pool.commands, fmt.Sprintf("%d %s %v", c.n, cmd, args[0]))
+	switch {
+	case cmd == "FT.AGGREGATE":
+		return []interface{}{[]interface{}{int64(2), []interface{}{[]byte("user"), []byte("foo")}}, int64(7)}, nil
+	case args[0] == "READ":
+		c.err = errors.New("i/o timeout")
+		return nil, c.err
+	}
+	return "OK", nil
+}
+
+func TestAggregateCursorBrokenConn(t *testing.T) {
+	pool := &cursorPool{}
+	c := NewClientFromPool(pool, "idx")
+	cur := c.AggregateCursor(NewAggregateQuery(NewQuery("*")), DefaultCursorOptions)
+	if !cur.Next() {
+		t.Fatal(cur.Err())
+	}
+	if cur.Next() || cur.Err() == nil {
+		t.Fatal("expected the read to fail")
+	}
+	if err := cur.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// the cursor is deleted on a new connection
+	want := []string{"1 FT.AGGREGATE idx", "1 FT.CURSOR READ", "2 FT.CURSOR DEL"}
+	if !reflect.DeepEqual(pool.commands, want) {
+		t.Errorf("commands = %v, want %v", pool.commands, want)
+	}
+}
@@ -1,6 +1,7 @@
 package redisearch
 
 import (
+	"context"
 	"strconv"
 
 	"github.com/garyburd/redigo/redis"
@@ -8,34 +9,61 @@ import (
 
 // Autocompleter implements a redisearch auto-completer API
 type Autocompleter struct {
-	pool *redis.Pool
+	pool ConnPool
 	name string
 }
 
 // NewAutocompleter creates a new Autocompleter with the given host and key name
 func NewAutocompleter(addr, name string) *Autocompleter {
+	return NewAutocompleterOptions(addr, name, DefaultClientOptions)
+}
+
+// NewAutocompleterOptions is like NewAutocompleter, but dials and pools the connections with the given options
+func NewAutocompleterOptions(addr, name string, opts ClientOptions) *Autocompleter {
+	return NewAutocompleterFromPool(NewSingleHostPoolOptions(addr, opts), name)
+}
+
+// NewAutocompleterFromPool creates a new Autocompleter on the key name, using connections from the pool
+func NewAutocompleterFromPool(pool ConnPool, name string) *Autocompleter {
 	return &Autocompleter{
-		pool: redis.NewPool(func() (redis.Conn, error) {
-			return redis.Dial("tcp", addr)
-		}, maxConns),
+		pool: pool,
 		name: name,
 	}
 }
 
+// NewAutocompleterFromExecutor creates a new Autocompleter on the key name, running its commands with the executor
+func NewAutocompleterFromExecutor(e Executor, name string) *Autocompleter {
+	return NewAutocompleterFromPool(NewExecutorPool(e), name)
+}
+
 // Delete deletes the Autocompleter key for this AC
 func (a *Autocompleter) Delete() error {
+	return a.DeleteContext(context.Background())
+}
 
-	conn := a.pool.Get()
+// DeleteContext is like Delete, but honors the context's cancellation and deadline
+func (a *Autocompleter) DeleteContext(ctx context.Context) error {
+	conn, err := getConn(ctx, a.pool)
+	if err != nil {
+		return err
+	}
 	defer conn.Close()
 
-	_, err := conn.Do("DEL", a.name)
+	_, err = conn.Do("DEL", a.name)
 	return err
 }
 
 // AddTerms pushes new term suggestions to the index
 func (a *Autocompleter) AddTerms(terms ...Suggestion) error {
+	return a.AddTermsContext(context.Background(), terms...)
+}
 
-	conn := a.pool.Get()
+// AddTermsContext is like AddTerms, but honors the context's cancellation and deadline
+func (a *Autocompleter) AddTermsContext(ctx context.Context, terms ...Suggestion) error {
+	conn, err := getConn(ctx, a.pool)
+	if err != nil {
+		return err
+	}
 	defer conn.Close()
 
 	i := 0
@@ -69,7 +97,17 @@ func (a *Autocompleter) AddTerms(terms ...Suggestion) error {
 //
 // Deprecated: Please use SuggestOpts() instead
 func (a *Autocompleter) Suggest(prefix string, num int, fuzzy bool) ([]Suggestion, error) {
-	conn := a.pool.Get()
+	return a.SuggestContext(context.Background(), prefix, num, fuzzy)
+}
+
+// SuggestContext is like Suggest, but honors the context's cancellation and deadline
+//
+// Deprecated: Please use SuggestOptsContext() instead
+func (a *Autocompleter) SuggestContext(ctx context.Context, prefix string, num int, fuzzy bool) ([]Suggestion, error) {
+	conn, err := getConn(ctx, a.pool)
+	if err != nil {
+		return nil, err
+	}
 	defer conn.Close()
 
 	args := redis.Args{a.name, prefix, "MAX", num, "WITHSCORES"}
@@ -101,7 +139,15 @@ func (a *Autocompleter) Suggest(prefix string, num int, fuzzy bool) ([]Suggestio
 // If SuggestOptions.Fuzzy is set, we also complete for prefixes that are in 1 Levenshten distance from the
 // given prefix
 func (a *Autocompleter) SuggestOpts(prefix string, opts SuggestOptions) ([]Suggestion, error) {
-	conn := a.pool.Get()
+	return a.SuggestOptsContext(context.Background(), prefix, opts)
+}
+
+// SuggestOptsContext is like SuggestOpts, but honors the context's cancellation and deadline
+func (a *Autocompleter) SuggestOptsContext(ctx context.Context, prefix string, opts SuggestOptions) ([]Suggestion, error) {
+	conn, err := getConn(ctx, a.pool)
+	if err != nil {
+		return nil, err
+	}
 	defer conn.Close()
 
 	inc := 1
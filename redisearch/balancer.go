@@ -0,0 +1,91 @@
+package redisearch
+
+import (
+	"math/rand"
+	"sync/atomic"
+)
+
+// HostInfo describes a host of a MultiHostPool
+type HostInfo struct {
+	Addr string
+	// Weight is the relative share of connections the host should get with a weighted balancer
+	Weight int
+	// Active is the number of connections currently borrowed from the host
+	Active int
+	// Healthy is false while the host is ejected after failures
+	Healthy bool
+	// Failures is the number of consecutive failures of the host
+	Failures int
+}
+
+// Balancer selects which host a MultiHostPool connects to. Pick is given a non empty list of
+// candidate hosts, and returns the index of the selected one. It must be safe for concurrent use
+type Balancer interface {
+	Pick(hosts []HostInfo) int
+}
+
+// BalancerFunc is an adapter allowing the use of an ordinary function as a Balancer
+type BalancerFunc func(hosts []HostInfo) int
+
+// Pick calls f(hosts)
+func (f BalancerFunc) Pick(hosts []HostInfo) int {
+	return f(hosts)
+}
+
+// NewRandomBalancer creates a balancer selecting hosts uniformly at random
+func NewRandomBalancer() Balancer {
+	return BalancerFunc(func(hosts []HostInfo) int {
+		return rand.Intn(len(hosts))
+	})
+}
+
+type roundRobinBalancer struct {
+	next uint64
+}
+
+func (b *roundRobinBalancer) Pick(hosts []HostInfo) int {
+	return int((atomic.AddUint64(&b.next, 1) - 1) % uint64(len(hosts)))
+}
+
+// NewRoundRobinBalancer creates a balancer selecting the hosts in turn
+func NewRoundRobinBalancer() Balancer {
+	return &roundRobinBalancer{}
+}
+
+// NewLeastActiveBalancer creates a balancer selecting the host with the fewest borrowed connections
+func NewLeastActiveBalancer() Balancer {
+	return BalancerFunc(func(hosts []HostInfo) int {
+		best := 0
+		for i, h := range hosts {
+			if h.Active < hosts[best].Active {
+				best = i
+			}
+		}
+		return best
+	})
+}
+
+// NewWeightedBalancer creates a balancer selecting hosts at random, in proportion to their weight.
+// Hosts with a weight below 1 are treated as having a weight of 1
+func NewWeightedBalancer() Balancer {
+	return BalancerFunc(func(hosts []HostInfo) int {
+		total := 0
+		for _, h := range hosts {
+			total += hostWeight(h)
+		}
+		n := rand.Intn(total)
+		for i, h := range hosts {
+			if n -= hostWeight(h); n < 0 {
+				return i
+			}
+		}
+		return len(hosts) - 1
+	})
+}
+
+func hostWeight(h HostInfo) int {
+	if h.Weight < 1 {
+		return 1
+	}
+	return h.Weight
+}
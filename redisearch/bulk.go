@@ -0,0 +1,293 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// ErrBulkIndexerClosed is returned when adding documents to a closed BulkIndexer
+var ErrBulkIndexerClosed = errors.New("Bulk indexer is closed")
+
+// BulkIndexerOptions configure the batching, concurrency and retries of a BulkIndexer
+type BulkIndexerOptions struct {
+	// Workers is the number of batches indexed concurrently, each on its own connection
+	Workers int
+	// BatchSize is the maximal number of documents sent in a single pipeline
+	BatchSize int
+	// BatchBytes is the maximal estimated size of the documents in a single pipeline. Zero disables the limit
+	BatchBytes int
+	// FlushInterval is the time after which a partial batch is sent. Zero disables periodic flushes
+	FlushInterval time.Duration
+	// MaxRetries is the number of times a document that failed with a transient error is retried
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled on every retry
+	RetryBackoff time.Duration
+	// Indexing are the options used for every indexed document
+	Indexing IndexingOptions
+	// OnResult, if set, is called with the outcome of every document. err is nil if the document was
+	// indexed. It is called concurrently from the workers
+	OnResult func(doc Document, err error)
+}
+
+// DefaultBulkIndexerOptions are the default options for bulk indexing
+var DefaultBulkIndexerOptions = BulkIndexerOptions{
+	Workers:       4,
+	BatchSize:     500,
+	BatchBytes:    4 * 1024 * 1024,
+	FlushInterval: time.Second,
+	MaxRetries:    3,
+	RetryBackoff:  100 * time.Millisecond,
+	Indexing:      DefaultIndexingOptions,
+}
+
+// BulkIndexerStats are the counters of a BulkIndexer
+type BulkIndexerStats struct {
+	// Indexed is the number of documents indexed successfully
+	Indexed uint64
+	// Failed is the number of documents that could not be indexed
+	Failed uint64
+	// Retried is the number of document retries after transient errors
+	Retried uint64
+	// Batches is the number of pipelines sent
+	Batches uint64
+}
+
+// BulkIndexer indexes documents added over time in batches, using concurrent pipelines.
+// Add blocks when all the workers are busy, applying backpressure on the producer.
+//
+// Documents whose reply was lost to a connection error are retried, so they may be
+// sent twice; use IndexingOptions.Replace to make retries idempotent.
+type BulkIndexer struct {
+	client *Client
+	opts   BulkIndexerOptions
+	ctx    context.Context
+
+	docs    chan Document
+	batches chan []Document
+	wg      sync.WaitGroup
+
+	mu        sync.RWMutex
+	closed    bool
+	closeOnce sync.Once
+	closeErr  error
+
+	stats BulkIndexerStats
+}
+
+// NewBulkIndexer creates a bulk indexer on the client's index and starts its workers.
+// Close must be called to flush the pending documents and stop the workers
+func (i *Client) NewBulkIndexer(opts BulkIndexerOptions) *BulkIndexer {
+	return i.NewBulkIndexerContext(context.Background(), opts)
+}
+
+// NewBulkIndexerContext is like NewBulkIndexer, but sends the batches with the context. Once it is
+// done, the documents that were not sent fail with its error, and are not retried
+func (i *Client) NewBulkIndexerContext(ctx context.Context, opts BulkIndexerOptions) *BulkIndexer {
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBulkIndexerOptions.BatchSize
+	}
+
+	b := &BulkIndexer{
+		client:  i,
+		opts:    opts,
+		ctx:     ctx,
+		docs:    make(chan Document, opts.BatchSize),
+		batches: make(chan []Document),
+	}
+
+	b.wg.Add(opts.Workers)
+	for w := 0; w < opts.Workers; w++ {
+		go b.worker()
+	}
+	go b.batcher()
+	return b
+}
+
+// Add queues a document for indexing, blocking while the indexer is saturated
+func (b *BulkIndexer) Add(doc Document) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrBulkIndexerClosed
+	}
+	b.docs <- doc
+	return nil
+}
+
+// Close flushes the pending documents, waits for all the batches to be indexed and stops the workers.
+// It returns an error if any document failed to index
+func (b *BulkIndexer) Close() error {
+	b.closeOnce.Do(func() {
+		b.mu.Lock()
+		b.closed = true
+		close(b.docs)
+		b.mu.Unlock()
+
+		b.wg.Wait()
+		if failed := atomic.LoadUint64(&b.stats.Failed); failed > 0 {
+			b.closeErr = fmt.Errorf("%d documents failed to index", failed)
+		}
+	})
+	return b.closeErr
+}
+
+// Stats returns a snapshot of the indexer's counters
+func (b *BulkIndexer) Stats() BulkIndexerStats {
+	return BulkIndexerStats{
+		Indexed: atomic.LoadUint64(&b.stats.Indexed),
+		Failed:  atomic.LoadUint64(&b.stats.Failed),
+		Retried: atomic.LoadUint64(&b.stats.Retried),
+		Batches: atomic.LoadUint64(&b.stats.Batches),
+	}
+}
+
+// batcher groups incoming documents into batches by count and size
+func (b *BulkIndexer) batcher() {
+	defer close(b.batches)
+
+	var tick <-chan time.Time
+	if b.opts.FlushInterval > 0 {
+		ticker := time.NewTicker(b.opts.FlushInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	var batch []Document
+	size := 0
+	flush := func() {
+		if len(batch) > 0 {
+			b.batches <- batch
+			batch = nil
+			size = 0
+		}
+	}
+
+	for {
+		select {
+		case doc, ok := <-b.docs:
+			if !ok {
+				flush()
+				return
+			}
+			sz := doc.EstimateSize()
+			if b.opts.BatchBytes > 0 && size+sz > b.opts.BatchBytes {
+				flush()
+			}
+			batch = append(batch, doc)
+			size += sz
+			if len(batch) >= b.opts.BatchSize {
+				flush()
+			}
+		case <-tick:
+			flush()
+		}
+	}
+}
+
+func (b *BulkIndexer) worker() {
+	defer b.wg.Done()
+	for batch := range b.batches {
+		b.indexBatch(batch)
+	}
+}
+
+// indexBatch sends a batch, retrying the documents that failed with transient errors
+func (b *BulkIndexer) indexBatch(batch []Document) {
+	backoff := b.opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		failed, errs := b.sendBatch(batch)
+		if len(failed) == 0 {
+			return
+		}
+		if attempt >= b.opts.MaxRetries || b.ctx.Err() != nil {
+			for ii, doc := range failed {
+				b.report(doc, errs[ii])
+			}
+			return
+		}
+		atomic.AddUint64(&b.stats.Retried, uint64(len(failed)))
+		select {
+		case <-time.After(backoff):
+		case <-b.ctx.Done():
+		}
+		backoff *= 2
+		batch = failed
+	}
+}
+
+// sendBatch indexes the batch in a single pipeline, reporting the outcome of every document
+// except those that failed with a transient error, which are returned for a retry
+func (b *BulkIndexer) sendBatch(batch []Document) (failed []Document, errs []error) {
+	atomic.AddUint64(&b.stats.Batches, 1)
+
+	conn, err := getConn(b.ctx, b.client.indexingPool())
+	if err != nil {
+		return batch, repeatError(err, len(batch))
+	}
+	defer conn.Close()
+
+	for _, doc := range batch {
+		if err := conn.Send("FT.ADD", b.client.addArgs(b.opts.Indexing, doc)...); err != nil {
+			return batch, repeatError(classifyError(err), len(batch))
+		}
+	}
+	if err := conn.Flush(); err != nil {
+		return batch, repeatError(classifyError(err), len(batch))
+	}
+
+	for _, doc := range batch {
+		// the connection classifies the error replies
+		_, err := conn.Receive()
+		if err != nil && isTransientError(err) {
+			failed = append(failed, doc)
+			errs = append(errs, err)
+			continue
+		}
+		b.report(doc, err)
+	}
+	return
+}
+
+func (b *BulkIndexer) report(doc Document, err error) {
+	if err != nil {
+		atomic.AddUint64(&b.stats.Failed, 1)
+	} else {
+		atomic.AddUint64(&b.stats.Indexed, 1)
+	}
+	if b.opts.OnResult != nil {
+		b.opts.OnResult(doc, err)
+	}
+}
+
+func repeatError(err error, n int) []error {
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
+
+// isTransientError reports whether an operation that failed with err may succeed if retried:
+// connection errors, and server errors signaling a temporary condition
+func isTransientError(err error) bool {
+	var rerr redis.Error
+	if !errors.As(err, &rerr) {
+		return true
+	}
+	for _, prefix := range []string{"LOADING", "BUSY", "TRYAGAIN", "CLUSTERDOWN", "MASTERDOWN"} {
+		if strings.HasPrefix(string(rerr), prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePool hands out connections that answer FT.ADD with the reply returned by the reply function
+type fakePool struct {
+	sync.Mutex
+	reply func(docId string) error
+	sent  []string
+}
+
+func (p *fakePool) Get() redis.Conn { return &fakeConn{pool: p} }
+func (p *fakePool) Close() error    { return nil }
+
+type fakeConn struct {
+	pool    *fakePool
+	pending []string
+}
+
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Err() error   { return nil }
+func (c *fakeConn) Flush() error { return nil }
+func (c *fakeConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	if cmd == "" {
+		return nil, nil
+	}
+	if err := c.Send(cmd, args...); err != nil {
+		return nil, err
+	}
+	return c.Receive()
+}
+func (c *fakeConn) Send(cmd string, args ...interface{}) error {
+	c.pool.Lock()
+	defer c.pool.Unlock()
+	id := args[1].(string)
+	c.pool.sent = append(c.pool.sent, id)
+	c.pending = append(c.pending, id)
+	return nil
+}
+func (c *fakeConn) Receive() (interface{}, error) {
+	id := c.pending[0]
+	c.pending = c.pending[1:]
+	c.pool.Lock()
+	defer c.pool.Unlock()
+	if err := c.pool.reply(id); err != nil {
+		return nil, err
+	}
+	return "OK", nil
+}
+
+func TestBulkIndexer(t *testing.T) {
+	attempts := map[string]int{}
+	pool := &fakePool{}
+	pool.reply = func(id string) error {
+		attempts[id]++
+		switch {
+		case id == "exists":
+			return redis.Error("Document already exists")
+		case id == "flaky" && attempts[id] < 3:
+			return redis.Error("LOADING Redis is loading the dataset in memory")
+		case id == "down":
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	c := &Client{pool: pool, name: "idx"}
+
+	var mu sync.Mutex
+	results := map[string]error{}
+	opts := DefaultBulkIndexerOptions
+	opts.BatchSize = 3
+	opts.RetryBackoff = time.Millisecond
+	opts.OnResult = func(doc Document, err error) {
+		mu.Lock()
+		results[doc.Id] = err
+		mu.Unlock()
+	}
+
+	b := c.NewBulkIndexer(opts)
+	for _, id := range []string{"a", "b", "exists", "flaky", "c", "down", "d"} {
+		assert.Nil(t, b.Add(NewDocument(id, 1).Set("foo", "bar")))
+	}
+	assert.NotNil(t, b.Close())
+	assert.Equal(t, ErrBulkIndexerClosed, b.Add(NewDocument("e", 1)))
+
+	stats := b.Stats()
+	assert.Equal(t, uint64(5), stats.Indexed)
+	assert.Equal(t, uint64(2), stats.Failed)
+	assert.Equal(t, uint64(2+3), stats.Retried)
+	assert.Equal(t, 7, len(results))
+	assert.Nil(t, results["flaky"])
+	assert.True(t, errors.Is(results["exists"], ErrDocumentExists))
+	assert.NotNil(t, results["down"])
+	assert.Equal(t, 1, attempts["exists"])
+	assert.Equal(t, 4, attempts["down"])
+}
+
+func TestBulkIndexerContext(t *testing.T) {
+	pool := &fakePool{reply: func(id string) error { return nil }}
+	c := &Client{pool: pool, name: "idx"}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var mu sync.Mutex
+	var errs []error
+	opts := DefaultBulkIndexerOptions
+	opts.OnResult = func(doc Document, err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+	b := c.NewBulkIndexerContext(ctx, opts)
+	for _, id := range []string{"a", "b"} {
+		assert.Nil(t, b.Add(NewDocument(id, 1).Set("foo", "bar")))
+	}
+	assert.NotNil(t, b.Close())
+
+	// the documents fail with the context's error, without being sent or retried
+	assert.Empty(t, pool.sent)
+	assert.Equal(t, []error{context.Canceled, context.Canceled}, errs)
+	assert.Equal(t, BulkIndexerStats{Failed: 2, Batches: 1}, b.Stats())
+}
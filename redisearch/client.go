@@ -1,11 +1,13 @@
 package redisearch
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"log"
 
@@ -25,6 +27,10 @@ type Options struct {
 
 	NoOffsetVectors bool
 
+	// MaxTextFields creates the index with room for 128 TEXT fields instead of 32. It is needed for
+	// more than 32 TEXT fields, unless NoFieldFlags is set
+	MaxTextFields bool
+
 	Stopwords []string
 }
 
@@ -34,19 +40,35 @@ var DefaultOptions = Options{
 	NoFieldFlags:    false,
 	NoFrequencies:   false,
 	NoOffsetVectors: false,
+	MaxTextFields:   false,
 	Stopwords:       nil,
 }
 
 // Client is an interface to redisearch's redis commands
 type Client struct {
-	pool ConnPool
-	name string
+	// pool is used for querying, and for indexing when indexPool is not set
+	pool      ConnPool
+	indexPool ConnPool
+	name      string
+	// schema holds the schemaRef used to convert returned documents to typed values
+	schema atomic.Value
+}
+
+// indexingPool returns the pool used for indexing and for changing the index
+func (i *Client) indexingPool() ConnPool {
+	if i.indexPool != nil {
+		return i.indexPool
+	}
+	return i.pool
 }
 
 func (i *Client) Close() error {
 	if i.pool != nil {
 		i.pool.Close()
 	}
+	if i.indexPool != nil && i.indexPool != i.pool {
+		i.indexPool.Close()
+	}
 	return nil
 }
 
@@ -56,26 +78,66 @@ var maxConns = 500
 // Addr can be a single host:port pair, or a comma separated list of host:port,host:port...
 // In the case of multiple hosts we create a multi-pool and select connections at random
 func NewClient(addr, name string) *Client {
+	return NewClientOptions(addr, name, DefaultClientOptions)
+}
 
-	addrs := strings.Split(addr, ",")
-	var pool ConnPool
-	if len(addrs) == 1 {
-		pool = NewSingleHostPool(addrs[0])
-	} else {
-		pool = NewMultiHostPool(addrs)
-	}
-	ret := &Client{
+// NewClientOptions is like NewClient, but dials and pools the connections with the given options
+func NewClientOptions(addr, name string, opts ClientOptions) *Client {
+	return NewClientFromPool(NewPool(addr, opts), name)
+}
+
+// NewClientFromPool creates a new client sending all its commands to the given pool
+func NewClientFromPool(pool ConnPool, name string) *Client {
+	return &Client{
 		pool: pool,
 		name: name,
 	}
+}
 
-	return ret
+// NewSplitClient creates a new client that sends queries (Search, Explain, Info and Aggregate) to
+// queryPool, and indexing and index changes (IndexOptions, Delete, CreateIndex and Drop) to indexPool,
+// so that indexing bursts do not starve queries. The pools can point at different hosts, e.g. at
+// replicas for queries
+func NewSplitClient(queryPool, indexPool ConnPool, name string) *Client {
+	return &Client{
+		pool:      queryPool,
+		indexPool: indexPool,
+		name:      name,
+	}
 }
 
 // CreateIndex configues the index and creates it on redis
 func (i *Client) CreateIndex(s *Schema) error {
+	return i.CreateIndexContext(context.Background(), s)
+}
+
+// CreateIndexContext is like CreateIndex, but honors the context's cancellation and deadline
+func (i *Client) CreateIndexContext(ctx context.Context, s *Schema) error {
+	args, err := i.createArgs(s)
+	if err != nil {
+		return err
+	}
+
+	conn, err := getConn(ctx, i.indexingPool())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Do("FT.CREATE", args...)
+	return err
+}
+
+// createArgs validates the schema and builds the FT.CREATE arguments
+func (i *Client) createArgs(s *Schema) (redis.Args, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
 	args := redis.Args{i.name}
 	// Set flags based on options
+	if s.Options.MaxTextFields {
+		args = append(args, "MAXTEXTFIELDS")
+	}
 	if s.Options.NoFieldFlags {
 		args = append(args, "NOFIELDS")
 	}
@@ -94,79 +156,160 @@ func (i *Client) CreateIndex(s *Schema) error {
 
 	args = append(args, "SCHEMA")
 	for _, f := range s.Fields {
+		fargs, err := serializeField(f)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, fargs...)
+	}
+	return args, nil
+}
 
-		switch f.Type {
-		case TextField:
+// serializeField renders the arguments defining a field in FT.CREATE and FT.ALTER
+func serializeField(f Field) (redis.Args, error) {
+	var args redis.Args
+	switch f.Type {
+	case TextField:
+
+		args = append(args, f.Name, "TEXT")
+		if f.Options != nil {
+			opts, ok := f.Options.(TextFieldOptions)
+			if !ok {
+				return nil, errors.New("Invalid text field options type")
+			}
 
-			args = append(args, f.Name, "TEXT")
-			if f.Options != nil {
-				opts, ok := f.Options.(TextFieldOptions)
-				if !ok {
-					return errors.New("Invalid text field options type")
-				}
+			if opts.Weight != 0 && opts.Weight != 1 {
+				args = append(args, "WEIGHT", opts.Weight)
+			}
+			if opts.NoStem {
+				args = append(args, "NOSTEM")
+			}
 
-				if opts.Weight != 0 && opts.Weight != 1 {
-					args = append(args, "WEIGHT", opts.Weight)
-				}
-				if opts.NoStem {
-					args = append(args, "NOSTEM")
-				}
+			if opts.PhoneticMatcher != "" {
+				args = append(args, "PHONETIC", opts.PhoneticMatcher)
+			} else if opts.DMENPhonetic {
+				args = append(args, "PHONETIC", "dm:en")
+			}
+			if opts.Sortable {
+				args = append(args, "SORTABLE")
+			}
 
-				if opts.DMENPhonetic {
-					args = append(args, "PHONETIC", "dm:en")
-				}
-				if opts.Sortable {
-					args = append(args, "SORTABLE")
-				}
+			if opts.NoIndex {
+				args = append(args, "NOINDEX")
+			}
 
-				if opts.NoIndex {
-					args = append(args, "NOINDEX")
-				}
+		}
 
+	case NumericField:
+		args = append(args, f.Name, "NUMERIC")
+		if f.Options != nil {
+			opts, ok := f.Options.(NumericFieldOptions)
+			if !ok {
+				return nil, errors.New("Invalid numeric field options type")
 			}
 
-		case NumericField:
-			args = append(args, f.Name, "NUMERIC")
-			if f.Options != nil {
-				opts, ok := f.Options.(NumericFieldOptions)
-				if !ok {
-					return errors.New("Invalid numeric field options type")
-				}
-
-				if opts.Sortable {
-					args = append(args, "SORTABLE")
-				}
-				if opts.NoIndex {
-					args = append(args, "NOINDEX")
-				}
+			if opts.Sortable {
+				args = append(args, "SORTABLE")
 			}
-		case TagField:
-			args = append(args, f.Name, "TAG")
-			if f.Options != nil {
-				opts, ok := f.Options.(TagFieldOptions)
-				if !ok {
-					return errors.New("Invalid tag field options type")
-				}
-				if opts.Separator != 0 {
-					args = append(args, "SEPARATOR", fmt.Sprintf("%c", opts.Separator))
+			if opts.NoIndex {
+				args = append(args, "NOINDEX")
+			}
+		}
+	case TagField:
+		args = append(args, f.Name, "TAG")
+		if f.Options != nil {
+			opts, ok := f.Options.(TagFieldOptions)
+			if !ok {
+				return nil, errors.New("Invalid tag field options type")
+			}
+			if opts.Separator != 0 {
+				args = append(args, "SEPARATOR", fmt.Sprintf("%c", opts.Separator))
 
-				}
-				if opts.Sortable {
-					args = append(args, "SORTABLE")
-				}
-				if opts.NoIndex {
-					args = append(args, "NOINDEX")
-				}
 			}
-		default:
-			return fmt.Errorf("Unsupported field type %v", f.Type)
+			if opts.Sortable {
+				args = append(args, "SORTABLE")
+			}
+			if opts.NoIndex {
+				args = append(args, "NOINDEX")
+			}
+		}
+	case GeoField:
+		args = append(args, f.Name, "GEO")
+		if f.Options != nil {
+			opts, ok := f.Options.(GeoFieldOptions)
+			if !ok {
+				return nil, errors.New("Invalid geo field options type")
+			}
+			if opts.NoIndex {
+				args = append(args, "NOINDEX")
+			}
 		}
+	default:
+		return nil, fmt.Errorf("Unsupported field type %v", f.Type)
+	}
+	return args, nil
+}
+
+// AlterAddField adds a field to the existing index with FT.ALTER. Only the documents indexed
+// afterwards are indexed on the new field
+func (i *Client) AlterAddField(f Field) error {
+	return i.AlterAddFieldsContext(context.Background(), f)
+}
 
+// AlterAddFields adds several fields to the existing index, one FT.ALTER at a time.
+// The fields are checked against the current schema first, so that none is added if any of
+// them is invalid or already exists. The fields before a server error are still added
+func (i *Client) AlterAddFields(fields ...Field) error {
+	return i.AlterAddFieldsContext(context.Background(), fields...)
+}
+
+// AlterAddFieldsContext is like AlterAddFields, but honors the context's cancellation and deadline
+func (i *Client) AlterAddFieldsContext(ctx context.Context, fields ...Field) error {
+	info, err := i.InfoContext(ctx)
+	if err != nil {
+		return err
 	}
+	existing := make(map[string]bool, len(info.Schema.Fields)+len(fields))
+	for _, f := range info.Schema.Fields {
+		existing[f.Name] = true
+	}
+	for _, f := range fields {
+		if f.Name == "" {
+			return errors.New("Invalid empty field name")
+		}
+		if existing[f.Name] {
+			return fmt.Errorf("Field %s: %w", f.Name, ErrFieldExists)
+		}
+		existing[f.Name] = true
+	}
+	// the schema is validated as a whole, since the limits depend on the existing fields and options
+	merged := Schema{Options: info.Schema.Options, Fields: append(append([]Field{}, info.Schema.Fields...), fields...)}
+	if err := merged.Validate(); err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		if err := i.alterAddField(ctx, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	conn := i.pool.Get()
+// alterAddField adds a field to the index without checking the schema
+func (i *Client) alterAddField(ctx context.Context, f Field) error {
+	fargs, err := serializeField(f)
+	if err != nil {
+		return err
+	}
+	conn, err := getConn(ctx, i.indexingPool())
+	if err != nil {
+		return err
+	}
 	defer conn.Close()
-	_, err := conn.Do("FT.CREATE", args...)
+
+	args := redis.Args{i.name, "SCHEMA", "ADD"}
+	_, err = conn.Do("FT.ALTER", append(args, fargs...)...)
 	return err
 }
 
@@ -186,70 +329,149 @@ var DefaultIndexingOptions = IndexingOptions{
 	Partial:  false,
 }
 
-// IndexOptions indexes multiple documents on the index, with optional Options passed to options
-func (i *Client) IndexOptions(opts IndexingOptions, docs ...Document) error {
+// addArgs builds the FT.ADD arguments for a single document
+func (i *Client) addArgs(opts IndexingOptions, doc Document) redis.Args {
+	args := make(redis.Args, 0, 6+len(doc.Properties))
+	args = append(args, i.name, doc.Id, doc.Score)
+	// apply options
+	if opts.NoSave {
+		args = append(args, "NOSAVE")
+	}
+	if opts.Language != "" {
+		args = append(args, "LANGUAGE", opts.Language)
+	}
 
-	conn := i.pool.Get()
-	defer conn.Close()
+	if opts.Partial {
+		opts.Replace = true
+	}
 
-	n := 0
-	var merr MultiError
+	if opts.Replace {
+		args = append(args, "REPLACE")
+		if opts.Partial {
+			args = append(args, "PARTIAL")
+		}
+	}
+
+	if doc.Payload != nil {
+		args = args.Add("PAYLOAD", doc.Payload)
+	}
 
+	args = append(args, "FIELDS")
+
+	for k, f := range doc.Properties {
+		args = append(args, k, f)
+	}
+	return args
+}
+
+// IndexResult is the outcome of indexing a single document
+type IndexResult struct {
+	Id string
+	// Err is nil if the document was indexed
+	Err error
+	// Exists is set if the document was rejected because it is already in the index
+	Exists bool
+}
+
+// IndexWithResults indexes multiple documents on the index, and returns the outcome of every
+// document in the order they were given. The returned error is only set if the documents could
+// not be sent at all, in which case it is also set on every result
+func (i *Client) IndexWithResults(opts IndexingOptions, docs ...Document) ([]IndexResult, error) {
+	return i.IndexWithResultsContext(context.Background(), opts, docs...)
+}
+
+// contextPipelineSize is the number of documents sent in each pipeline when indexing with a
+// cancelable context. The context is checked between pipelines, so that a cancellation never
+// leaves documents buffered on the connection
+const contextPipelineSize = 100
+
+// IndexWithResultsContext is like IndexWithResults, but honors the context's cancellation and deadline.
+// When the context is canceled, the documents that were not sent yet are not indexed, and their result
+// holds the context's error. Documents whose reply was pending when the deadline expired may or may
+// not have been indexed
+func (i *Client) IndexWithResultsContext(ctx context.Context, opts IndexingOptions, docs ...Document) ([]IndexResult, error) {
+	results := make([]IndexResult, len(docs))
 	for ii, doc := range docs {
-		args := make(redis.Args, 0, 6+len(doc.Properties))
-		args = append(args, i.name, doc.Id, doc.Score)
-		// apply options
-		if opts.NoSave {
-			args = append(args, "NOSAVE")
-		}
-		if opts.Language != "" {
-			args = append(args, "LANGUAGE", opts.Language)
-		}
+		results[ii].Id = doc.Id
+	}
 
-		if opts.Partial {
-			opts.Replace = true
+	fail := func(from int, err error) ([]IndexResult, error) {
+		for ii := from; ii < len(results); ii++ {
+			results[ii].Err = err
 		}
+		return results, err
+	}
+
+	conn, err := getConn(ctx, i.indexingPool())
+	if err != nil {
+		return fail(0, err)
+	}
+	defer conn.Close()
 
-		if opts.Replace {
-			args = append(args, "REPLACE")
-			if opts.Partial {
-				args = append(args, "PARTIAL")
+	size := len(docs)
+	if ctx.Done() != nil {
+		size = contextPipelineSize
+	}
+
+	for start := 0; start < len(docs); start += size {
+		if start > 0 {
+			if err := ctx.Err(); err != nil {
+				return fail(start, err)
 			}
 		}
-
-		if doc.Payload != nil {
-			args = args.Add("PAYLOAD", doc.Payload)
+		end := start + size
+		if end > len(docs) {
+			end = len(docs)
 		}
 
-		args = append(args, "FIELDS")
+		for _, doc := range docs[start:end] {
+			if err := conn.Send("FT.ADD", i.addArgs(opts, doc)...); err != nil {
+				return fail(start, err)
+			}
+		}
 
-		for k, f := range doc.Properties {
-			args = append(args, k, f)
+		if err := conn.Flush(); err != nil {
+			return fail(start, err)
 		}
 
-		if err := conn.Send("FT.ADD", args...); err != nil {
-			if merr == nil {
-				merr = NewMultiError(len(docs))
+		for ii := start; ii < end; ii++ {
+			if _, err := conn.Receive(); err != nil {
+				results[ii].Err = err
+				results[ii].Exists = isDocumentExistsError(err)
 			}
-			merr[ii] = err
-
-			return merr
 		}
-		n++
 	}
+	return results, nil
+}
+
+// isDocumentExistsError reports whether FT.ADD rejected a document because it is already indexed
+func isDocumentExistsError(err error) bool {
+	return errors.Is(classifyError(err), ErrDocumentExists)
+}
 
-	if err := conn.Flush(); err != nil {
+// IndexOptions indexes multiple documents on the index, with optional Options passed to options.
+// If some of the documents failed to index, a MultiError is returned with a *DocumentError
+// at the index of every failed document
+func (i *Client) IndexOptions(opts IndexingOptions, docs ...Document) error {
+	return i.IndexOptionsContext(context.Background(), opts, docs...)
+}
+
+// IndexOptionsContext is like IndexOptions, but honors the context's cancellation and deadline.
+// See IndexWithResultsContext for the state of the documents when the context is done
+func (i *Client) IndexOptionsContext(ctx context.Context, opts IndexingOptions, docs ...Document) error {
+	results, err := i.IndexWithResultsContext(ctx, opts, docs...)
+	if err != nil {
 		return err
 	}
 
-	for n > 0 {
-		if _, err := conn.Receive(); err != nil {
+	var merr MultiError
+	for ii, res := range results {
+		if res.Err != nil {
 			if merr == nil {
 				merr = NewMultiError(len(docs))
 			}
-			merr[n-1] = err
+			merr[ii] = &DocumentError{Id: res.Id, Err: res.Err}
 		}
-		n--
 	}
 
 	if merr == nil {
@@ -259,128 +481,141 @@ func (i *Client) IndexOptions(opts IndexingOptions, docs ...Document) error {
 	return merr
 }
 
-// convert the result from a redis query to a proper Document object
-func loadDocument(arr []interface{}, idIdx, scoreIdx, payloadIdx, fieldsIdx int) (Document, error) {
-
-	var score float64 = 1
-	var err error
-	if scoreIdx > 0 {
-		if score, err = strconv.ParseFloat(string(arr[idIdx+scoreIdx].([]byte)), 64); err != nil {
-			return Document{}, fmt.Errorf("Could not parse score: %s", err)
-		}
-	}
-
-	doc := NewDocument(string(arr[idIdx].([]byte)), float32(score))
+// Index indexes a list of documents with the default options
+func (i *Client) Index(docs ...Document) error {
+	return i.IndexOptions(DefaultIndexingOptions, docs...)
+}
 
-	if payloadIdx > 0 {
-		doc.Payload, _ = arr[idIdx+payloadIdx].([]byte)
-	}
+// IndexContext is like Index, but honors the context's cancellation and deadline
+func (i *Client) IndexContext(ctx context.Context, docs ...Document) error {
+	return i.IndexOptionsContext(ctx, DefaultIndexingOptions, docs...)
+}
 
-	if fieldsIdx > 0 {
-		lst := arr[idIdx+fieldsIdx].([]interface{})
-		for i := 0; i < len(lst); i += 2 {
-			var prop string
-			switch lst[i].(type) {
-			case []byte:
-				prop = string(lst[i].([]byte))
-			default:
-				prop = lst[i].(string)
-			}
+// Search searches the index for the given query, and returns documents,
+// the total number of results, or an error if something went wrong.
+// If some entries of the reply could not be decoded, the other documents are returned
+// along with a MultiError describing the malformed entries
+func (i *Client) Search(q *Query) (docs []Document, total int, err error) {
+	return i.SearchContext(context.Background(), q)
+}
 
-			var val interface{}
-			switch v := lst[i+1].(type) {
-			case []byte:
-				val = string(v)
-			default:
-				val = v
-			}
-			doc = doc.Set(prop, val)
-		}
+// SearchContext is like Search, but honors the context's cancellation and deadline
+func (i *Client) SearchContext(ctx context.Context, q *Query) (docs []Document, total int, err error) {
+	res, err := i.SearchWithResultContext(ctx, q)
+	if err != nil {
+		return nil, 0, err
 	}
-
-	return doc, nil
+	return res.Docs, res.Total, res.Err()
 }
 
-// Index indexes a list of documents with the default options
-func (i *Client) Index(docs ...Document) error {
-	return i.IndexOptions(DefaultIndexingOptions, docs...)
+// Explain Return a textual string explaining the query
+func (i *Client) Explain(q *Query) (string, error) {
+	return i.ExplainContext(context.Background(), q)
 }
 
-// Search searches the index for the given query, and returns documents,
-// the total number of results, or an error if something went wrong
-func (i *Client) Search(q *Query) (docs []Document, total int, err error) {
-	conn := i.pool.Get()
+// ExplainContext is like Explain, but honors the context's cancellation and deadline
+func (i *Client) ExplainContext(ctx context.Context, q *Query) (string, error) {
+	conn, err := getConn(ctx, i.pool)
+	if err != nil {
+		return "", err
+	}
 	defer conn.Close()
 
 	args := redis.Args{i.name}
 	args = append(args, q.serialize()...)
 
-	res, err := redis.Values(conn.Do("FT.SEARCH", args...))
-	if err != nil {
-		return
-	}
+	return redis.String(conn.Do("FT.EXPLAIN", args...))
+}
 
-	if total, err = redis.Int(res[0], nil); err != nil {
-		return
-	}
+// Drop the  Currentl just flushes the DB - note that this will delete EVERYTHING on the redis instance
+func (i *Client) Drop() error {
+	return i.DropContext(context.Background())
+}
 
-	docs = make([]Document, 0, len(res)-1)
+// DropContext is like Drop, but honors the context's cancellation and deadline
+func (i *Client) DropContext(ctx context.Context) error {
+	return i.drop(ctx, false)
+}
 
-	skip := 1
-	scoreIdx := -1
-	fieldsIdx := -1
-	payloadIdx := -1
-	if q.Flags&QueryWithScores != 0 {
-		scoreIdx = 1
-		skip++
+// drop drops the index, and its documents unless keepDocs is set. Documents are shared by
+// the indexes on the same keys, so they must be kept when another index still uses them
+func (i *Client) drop(ctx context.Context, keepDocs bool) error {
+	conn, err := getConn(ctx, i.indexingPool())
+	if err != nil {
+		return err
 	}
-	if q.Flags&QueryWithPayloads != 0 {
-		payloadIdx = skip
-		skip++
+	defer conn.Close()
+
+	args := redis.Args{i.name}
+	if keepDocs {
+		args = append(args, "KEEPDOCS")
 	}
+	_, err = conn.Do("FT.DROP", args...)
+	return err
+}
 
-	if q.Flags&QueryNoContent == 0 {
-		fieldsIdx = skip
-		skip++
+// withName returns a client on another index, sharing the client's pools
+func (i *Client) withName(name string) *Client {
+	return &Client{
+		pool:      i.pool,
+		indexPool: i.indexPool,
+		name:      name,
 	}
+}
 
-	if len(res) > skip {
-		for i := 1; i < len(res); i += skip {
+// AddAlias adds an alias to the index. Queries, Info and indexing may then use the alias
+// as the index name. It fails if the alias already exists
+func (i *Client) AddAlias(alias string) error {
+	return i.AddAliasContext(context.Background(), alias)
+}
 
-			if d, e := loadDocument(res, i, scoreIdx, payloadIdx, fieldsIdx); e == nil {
-				docs = append(docs, d)
-			} else {
-				log.Print("Error parsing doc: ", e)
-			}
-		}
-	}
-	return
+// AddAliasContext is like AddAlias, but honors the context's cancellation and deadline
+func (i *Client) AddAliasContext(ctx context.Context, alias string) error {
+	return i.aliasCommand(ctx, "FT.ALIASADD", alias, i.name)
 }
 
-// Explain Return a textual string explaining the query
-func (i *Client) Explain(q *Query) (string, error) {
-	conn := i.pool.Get()
-	defer conn.Close()
+// UpdateAlias points an alias at the index, atomically removing it from the index it was on, if any
+func (i *Client) UpdateAlias(alias string) error {
+	return i.UpdateAliasContext(context.Background(), alias)
+}
 
-	args := redis.Args{i.name}
-	args = append(args, q.serialize()...)
+// UpdateAliasContext is like UpdateAlias, but honors the context's cancellation and deadline
+func (i *Client) UpdateAliasContext(ctx context.Context, alias string) error {
+	return i.aliasCommand(ctx, "FT.ALIASUPDATE", alias, i.name)
+}
 
-	return redis.String(conn.Do("FT.EXPLAIN", args...))
+// DeleteAlias removes an alias. The index it was on is left unchanged
+func (i *Client) DeleteAlias(alias string) error {
+	return i.DeleteAliasContext(context.Background(), alias)
 }
 
-// Drop the  Currentl just flushes the DB - note that this will delete EVERYTHING on the redis instance
-func (i *Client) Drop() error {
-	conn := i.pool.Get()
+// DeleteAliasContext is like DeleteAlias, but honors the context's cancellation and deadline
+func (i *Client) DeleteAliasContext(ctx context.Context, alias string) error {
+	return i.aliasCommand(ctx, "FT.ALIASDEL", alias)
+}
+
+func (i *Client) aliasCommand(ctx context.Context, cmd string, args ...interface{}) error {
+	conn, err := getConn(ctx, i.indexingPool())
+	if err != nil {
+		return err
+	}
 	defer conn.Close()
 
-	_, err := conn.Do("FT.DROP", i.name)
+	_, err = conn.Do(cmd, args...)
 	return err
-
 }
 
 // Delete the document from the index, optionally delete the actual document
 func (i *Client) Delete(docId string, deleteDocument bool) (err error) {
-	conn := i.pool.Get()
+	return i.DeleteContext(context.Background(), docId, deleteDocument)
+}
+
+// DeleteContext is like Delete, but honors the context's cancellation and deadline
+func (i *Client) DeleteContext(ctx context.Context, docId string, deleteDocument bool) (err error) {
+	conn, err := getConn(ctx, i.indexingPool())
+	if err != nil {
+		return
+	}
 	defer conn.Close()
 
 	if deleteDocument {
@@ -435,18 +670,11 @@ func (info *IndexInfo) setTarget(key string, value interface{}) error {
 	return errors.New("setTarget: No handler defined for :" + key)
 }
 
-func sliceIndex(haystack []string, needle string) int {
-	for pos, elem := range haystack {
-		if elem == needle {
-			return pos
-		}
-	}
-	return -1
-}
-
-func (info *IndexInfo) loadSchema(values []interface{}, options []string) {
+// loadSchema rebuilds the schema from the fields, index options and stopwords reported by FT.INFO.
+// Stopwords are nil if the index uses the default list
+func (info *IndexInfo) loadSchema(values []interface{}, options []string, stopwords []string) {
 	// Values are a list of fields
-	scOptions := Options{}
+	scOptions := Options{Stopwords: stopwords}
 	for _, opt := range options {
 		switch strings.ToUpper(opt) {
 		case "NOFIELDS":
@@ -455,77 +683,83 @@ func (info *IndexInfo) loadSchema(values []interface{}, options []string) {
 			scOptions.NoFrequencies = true
 		case "NOOFFSETS":
 			scOptions.NoOffsetVectors = true
+		case "MAXTEXTFIELDS":
+			scOptions.MaxTextFields = true
 		}
 	}
 	sc := NewSchema(scOptions)
 	for _, specTmp := range values {
-		// spec, isArr := specTmp.([]string)
-		// if !isArr {
-		// 	panic("Value is not an array of strings!")
-		// }
-		rawSpec, err := redis.Values(specTmp, nil)
+		spec, err := redis.Strings(specTmp, nil)
 		if err != nil {
 			log.Printf("Warning: Couldn't read schema. %s\n", err.Error())
 			continue
 		}
-		spec := make([]string, 0)
-
-		// Convert all to string, if not already string
-		for _, elem := range rawSpec {
-			s, isString := elem.(string)
-			if !isString {
-				s, err = redis.String(elem, err)
-				if err != nil {
-					log.Printf("Warning: Couldn't read schema. %s\n", err.Error())
-					continue
-				}
-			}
-			spec = append(spec, s)
-		}
 		// Name, Type,
 		if len(spec) < 3 {
 			log.Printf("Invalid spec")
 			continue
 		}
 
-		var options []string
-		if len(spec) > 3 {
-			options = spec[3:]
-		} else {
-			options = []string{}
+		f, err := loadField(spec[0], spec[2], spec[3:])
+		if err != nil {
+			log.Printf("Warning: Couldn't read schema. %s\n", err.Error())
+			continue
 		}
+		sc = sc.AddField(f)
+	}
+	info.Schema = *sc
+}
 
-		f := Field{Name: spec[0]}
-		switch strings.ToUpper(spec[2]) {
-		case "NUMERIC":
-			f.Type = NumericField
-			nfOptions := NumericFieldOptions{}
-			f.Options = nfOptions
-			if sliceIndex(options, "SORTABLE") != -1 {
-				nfOptions.Sortable = true
+// loadField rebuilds a field from its type and options as reported by FT.INFO. Options are only
+// set when they differ from the defaults, as the field constructors do
+func loadField(name, typ string, options []string) (Field, error) {
+	d := fieldDefinition{Name: name, Type: typ}
+	for j := 0; j < len(options); j++ {
+		switch strings.ToUpper(options[j]) {
+		case "SORTABLE":
+			d.Sortable = true
+		case "NOSTEM":
+			d.NoStem = true
+		case "NOINDEX":
+			d.NoIndex = true
+		case "PHONETIC":
+			d.Phonetic = true
+			// the matcher, e.g. dm:en, may follow
+			if j+1 < len(options) && strings.Contains(options[j+1], ":") {
+				j++
+				d.PhoneticMatcher = options[j]
 			}
-		case "TEXT":
-			f.Type = TextField
-			tfOptions := TextFieldOptions{}
-			f.Options = tfOptions
-			if sliceIndex(options, "SORTABLE") != -1 {
-				tfOptions.Sortable = true
+		case "WEIGHT":
+			if j+1 < len(options) {
+				j++
+				w, err := strconv.ParseFloat(options[j], 32)
+				if err != nil {
+					return Field{}, fmt.Errorf("Invalid weight for field %s: %s", name, err)
+				}
+				d.Weight = float32(w)
 			}
-			if wIdx := sliceIndex(options, "WEIGHT"); wIdx != -1 && wIdx+1 != len(spec) {
-				weightString := options[wIdx+1]
-				weight64, _ := strconv.ParseFloat(weightString, 32)
-				tfOptions.Weight = float32(weight64)
+		case "SEPARATOR":
+			if j+1 < len(options) {
+				j++
+				d.Separator = options[j]
 			}
 		}
-		sc = sc.AddField(f)
 	}
-	info.Schema = *sc
+	return d.field()
 }
 
 // Info - Get information about the index. This can also be used to check if the
 // index exists
 func (i *Client) Info() (*IndexInfo, error) {
-	conn := i.pool.Get()
+	return i.InfoContext(context.Background())
+}
+
+// InfoContext is like Info, but honors the context's cancellation and deadline
+func (i *Client) InfoContext(ctx context.Context) (*IndexInfo, error) {
+	conn, err := getConn(ctx, i.pool)
+	if err != nil {
+		return nil, err
+	}
 	defer conn.Close()
 
 	res, err := redis.Values(conn.Do("FT.INFO", i.name))
@@ -536,6 +770,7 @@ func (i *Client) Info() (*IndexInfo, error) {
 	ret := IndexInfo{}
 	var schemaFields []interface{}
 	var indexOptions []string
+	var stopwords []string
 
 	// Iterate over the values
 	for ii := 0; ii < len(res); ii += 2 {
@@ -549,11 +784,17 @@ func (i *Client) Info() (*IndexInfo, error) {
 			indexOptions, _ = redis.Strings(res[ii+1], nil)
 		case "fields":
 			schemaFields, _ = redis.Values(res[ii+1], nil)
+		case "stopwords_list":
+			// only reported for indexes created with custom stopwords
+			stopwords, _ = redis.Strings(res[ii+1], nil)
+			if stopwords == nil {
+				stopwords = []string{}
+			}
 		}
 	}
 
 	if schemaFields != nil {
-		ret.loadSchema(schemaFields, indexOptions)
+		ret.loadSchema(schemaFields, indexOptions, stopwords)
 	}
 
 	return &ret, nil
@@ -0,0 +1,118 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadSchema(t *testing.T) {
+	spec := func(s ...string) []interface{} {
+		ret := make([]interface{}, len(s))
+		for i, e := range s {
+			ret[i] = []byte(e)
+		}
+		return ret
+	}
+	fields := []interface{}{
+		spec("title", "type", "TEXT", "WEIGHT", "1"),
+		spec("body", "type", "TEXT", "WEIGHT", "2.5", "NOSTEM", "PHONETIC", "dm:en"),
+		spec("name", "type", "TEXT", "WEIGHT", "1", "SORTABLE", "NOINDEX"),
+		spec("price", "type", "NUMERIC"),
+		spec("age", "type", "NUMERIC", "SORTABLE"),
+		spec("tags", "type", "TAG", "SEPARATOR", ","),
+		spec("cats", "type", "TAG", "SEPARATOR", ";", "SORTABLE"),
+		spec("location", "type", "GEO"),
+		spec("hidden", "type", "GEO", "NOINDEX"),
+		spec("bad", "type", "VECTOR"),
+		spec("short"),
+	}
+
+	want := NewSchema(Options{NoFieldFlags: true, NoOffsetVectors: true, MaxTextFields: true, Stopwords: []string{"foo", "bar"}}).
+		AddField(NewTextField("title")).
+		AddField(NewTextFieldOptions("body", TextFieldOptions{Weight: 2.5, NoStem: true, DMENPhonetic: true})).
+		AddField(NewTextFieldOptions("name", TextFieldOptions{Weight: 1, Sortable: true, NoIndex: true})).
+		AddField(NewNumericField("price")).
+		AddField(NewSortableNumericField("age")).
+		AddField(NewTagField("tags")).
+		AddField(NewTagFieldOptions("cats", TagFieldOptions{Separator: ';', Sortable: true})).
+		AddField(NewGeoField("location")).
+		AddField(NewGeoFieldOptions("hidden", GeoFieldOptions{NoIndex: true}))
+
+	info := IndexInfo{}
+	info.loadSchema(fields, []string{"NOFIELDS", "NOOFFSETS", "MAXTEXTFIELDS"}, []string{"foo", "bar"})
+	assert.Equal(t, *want, info.Schema)
+	// a schema read from the server can be created again
+	assert.Nil(t, info.Schema.Validate())
+
+	info.loadSchema(nil, nil, nil)
+	assert.Nil(t, info.Schema.Options.Stopwords)
+	assert.Empty(t, info.Schema.Fields)
+}
+
+func TestAlterAddFields(t *testing.T) {
+	var alters []string
+	c := NewClientFromExecutor(FuncExecutor{DoFunc: func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		switch args[0] {
+		case "FT.INFO":
+			return []interface{}{
+				"index_name", "idx",
+				"fields", []interface{}{[]interface{}{"title", "type", "TEXT", "WEIGHT", "1"}},
+			}, nil
+		case "FT.ALTER":
+			alters = append(alters, fmt.Sprint(args[1:]...))
+		}
+		return "OK", nil
+	}}, "idx")
+
+	assert.Nil(t, c.AlterAddFields(
+		NewTextFieldOptions("body", TextFieldOptions{Weight: 2, NoStem: true}),
+		NewTagFieldOptions("tags", TagFieldOptions{Separator: ';'}),
+	))
+	assert.Nil(t, c.AlterAddField(NewSortableNumericField("price")))
+	assert.Equal(t, []string{
+		fmt.Sprint("idx", "SCHEMA", "ADD", "body", "TEXT", "WEIGHT", float32(2), "NOSTEM"),
+		fmt.Sprint("idx", "SCHEMA", "ADD", "tags", "TAG", "SEPARATOR", ";"),
+		fmt.Sprint("idx", "SCHEMA", "ADD", "price", "NUMERIC", "SORTABLE"),
+	}, alters)
+
+	alters = nil
+	err := c.AlterAddFields(NewTextField("body"), NewTextField("title"))
+	assert.True(t, errors.Is(err, ErrFieldExists))
+	err = c.AlterAddFields(NewTextField("body"), NewTextField("body"))
+	assert.True(t, errors.Is(err, ErrFieldExists))
+	assert.NotNil(t, c.AlterAddField(Field{Name: "bad", Type: TextField, Options: NumericFieldOptions{}}))
+	assert.Empty(t, alters)
+}
+
+func TestAlterAddFieldsTextLimit(t *testing.T) {
+	var options []interface{}
+	var alters int
+	c := NewClientFromExecutor(FuncExecutor{DoFunc: func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		switch args[0] {
+		case "FT.INFO":
+			var fields []interface{}
+			for n := 0; n < maxTextFields; n++ {
+				fields = append(fields, []interface{}{fmt.Sprintf("f%d", n), "type", "TEXT", "WEIGHT", "1"})
+			}
+			return []interface{}{"index_name", "idx", "index_options", options, "fields", fields}, nil
+		case "FT.ALTER":
+			alters++
+		}
+		return "OK", nil
+	}}, "idx")
+
+	// the index is full, unless it was created with MAXTEXTFIELDS
+	err := c.AlterAddField(NewTextField("body"))
+	assert.EqualError(t, err, "[0] Too many TEXT fields: 33 are indexed, at most 32 can be without MaxTextFields\n")
+	assert.Equal(t, 0, alters)
+	assert.Nil(t, c.AlterAddField(NewNumericField("price")))
+	assert.Equal(t, 1, alters)
+
+	options = []interface{}{"MAXTEXTFIELDS"}
+	assert.Nil(t, c.AlterAddField(NewTextField("body")))
+	assert.Equal(t, 2, alters)
+}
@@ -0,0 +1,456 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+const (
+	// clusterSlots is the number of hash slots of a redis cluster
+	clusterSlots = 16384
+	// maxClusterRedirects bounds the MOVED and ASK redirects followed for a single command
+	maxClusterRedirects = 5
+	// minTopologyRefreshInterval rate limits the topology refreshes triggered by redirects and errors
+	minTopologyRefreshInterval = 100 * time.Millisecond
+)
+
+var errClusterPoolClosed = errors.New("Cluster pool is closed")
+
+// ClusterPool is a pool of connections to the nodes of a redis cluster. The slot map is discovered
+// with CLUSTER SLOTS on first use and refreshed after redirects and connection errors.
+//
+// Commands bearing a key, including the FT.SUG* commands, are routed to the master owning the key's
+// hash slot, following MOVED and ASK redirects. The other FT.* commands are sent to any master,
+// as the RediSearch coordinator distributes them across the cluster.
+//
+// A pipeline started with Send is bound to the node the first command is routed to; its replies
+// are not redirected, but a MOVED reply still updates the slot map for the next commands
+type ClusterPool struct {
+	opts  ClientOptions
+	seeds []string
+
+	mu      sync.RWMutex
+	pools   map[string]*redis.Pool
+	slots   []string // master address by slot, nil until the topology is loaded
+	masters []string
+	closed  bool
+
+	refreshMu   sync.Mutex
+	lastRefresh time.Time
+}
+
+// NewClusterPool creates a pool of connections to the cluster that the seed nodes are part of
+func NewClusterPool(seeds []string, opts ClientOptions) *ClusterPool {
+	return &ClusterPool{
+		opts:  opts,
+		seeds: seeds,
+		pools: make(map[string]*redis.Pool),
+	}
+}
+
+// NewClusterClient creates a new client connecting to the index name on a redis cluster
+func NewClusterClient(seeds []string, name string, opts ClientOptions) *Client {
+	return NewClientFromPool(NewClusterPool(seeds, opts), name)
+}
+
+// Get returns a connection routing every command to the right node of the cluster
+func (p *ClusterPool) Get() redis.Conn {
+	return &clusterConn{pool: p, ctx: context.Background()}
+}
+
+// GetContext is like Get, but the node connections are obtained with the context
+func (p *ClusterPool) GetContext(ctx context.Context) (redis.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &clusterConn{pool: p, ctx: ctx}, nil
+}
+
+func (p *ClusterPool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for _, pool := range p.pools {
+		pool.Close()
+	}
+	return nil
+}
+
+// Masters returns the addresses of the cluster's masters, as of the last topology refresh
+func (p *ClusterPool) Masters() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]string(nil), p.masters...)
+}
+
+// Refresh reloads the slot map from the first node that answers CLUSTER SLOTS
+func (p *ClusterPool) Refresh() error {
+	return p.refresh(true)
+}
+
+func (p *ClusterPool) refresh(force bool) error {
+	p.refreshMu.Lock()
+	defer p.refreshMu.Unlock()
+	if !force && time.Since(p.lastRefresh) < minTopologyRefreshInterval {
+		return nil
+	}
+	p.lastRefresh = time.Now()
+
+	// the known masters are asked first, the seeds may have left the cluster
+	p.mu.RLock()
+	addrs := append(append([]string(nil), p.masters...), p.seeds...)
+	p.mu.RUnlock()
+
+	var lastErr error = ErrNoHosts
+	for _, addr := range addrs {
+		slots, masters, err := p.fetchSlots(addr)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		p.mu.Lock()
+		p.slots, p.masters = slots, masters
+		p.mu.Unlock()
+		return nil
+	}
+	return lastErr
+}
+
+func (p *ClusterPool) fetchSlots(addr string) ([]string, []string, error) {
+	conn, err := p.nodeConn(context.Background(), addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer conn.Close()
+	reply, err := redis.Values(conn.Do("CLUSTER", "SLOTS"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseClusterSlots(reply, addr)
+}
+
+// parseClusterSlots converts a CLUSTER SLOTS reply to the master address of every slot.
+// Nodes reported without an IP are reachable at the host the reply came from
+func parseClusterSlots(reply []interface{}, from string) (slots []string, masters []string, err error) {
+	fromHost, _, _ := net.SplitHostPort(from)
+	slots = make([]string, clusterSlots)
+	seen := make(map[string]bool)
+	for _, v := range reply {
+		r, err := redis.Values(v, nil)
+		if err != nil || len(r) < 3 {
+			return nil, nil, fmt.Errorf("Invalid slot range: %v", v)
+		}
+		start, err1 := redis.Int(r[0], nil)
+		end, err2 := redis.Int(r[1], nil)
+		if err1 != nil || err2 != nil || start < 0 || end < start || end >= clusterSlots {
+			return nil, nil, fmt.Errorf("Invalid slot range: %v-%v", r[0], r[1])
+		}
+		node, err := redis.Values(r[2], nil)
+		if err != nil || len(node) < 2 {
+			return nil, nil, fmt.Errorf("Invalid slot range node: %v", r[2])
+		}
+		host, err1 := redis.String(node[0], nil)
+		port, err2 := redis.Int(node[1], nil)
+		if err1 != nil || err2 != nil {
+			return nil, nil, fmt.Errorf("Invalid slot range node: %v", r[2])
+		}
+		if host == "" {
+			host = fromHost
+		}
+		addr := net.JoinHostPort(host, strconv.Itoa(port))
+		for slot := start; slot <= end; slot++ {
+			slots[slot] = addr
+		}
+		if !seen[addr] {
+			seen[addr] = true
+			masters = append(masters, addr)
+		}
+	}
+	return slots, masters, nil
+}
+
+// moved records the new owner of a slot after a MOVED redirect, and reloads the whole
+// slot map in the background since a redirect usually means more slots moved
+func (p *ClusterPool) moved(slot int, addr string) {
+	p.mu.Lock()
+	if p.slots != nil && slot >= 0 && slot < clusterSlots {
+		p.slots[slot] = addr
+	}
+	p.mu.Unlock()
+	go p.refresh(false)
+}
+
+// nodeFor returns the address of the node a command should be sent to
+func (p *ClusterPool) nodeFor(cmd string, args []interface{}) string {
+	if key, ok := commandKey(cmd, args); ok {
+		p.mu.RLock()
+		loaded := p.slots != nil
+		p.mu.RUnlock()
+		if !loaded {
+			p.refresh(false)
+		}
+
+		p.mu.RLock()
+		defer p.mu.RUnlock()
+		if p.slots != nil {
+			if addr := p.slots[hashSlot(key)]; addr != "" {
+				return addr
+			}
+		}
+		return p.anyNodeLocked()
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.anyNodeLocked()
+}
+
+func (p *ClusterPool) anyNodeLocked() string {
+	nodes := p.masters
+	if len(nodes) == 0 {
+		nodes = p.seeds
+	}
+	if len(nodes) == 0 {
+		return ""
+	}
+	return nodes[rand.Intn(len(nodes))]
+}
+
+// nodeConn gets a connection to the node at addr, creating the node's pool if needed.
+// A failure to connect triggers a topology refresh, as the node may have failed over
+func (p *ClusterPool) nodeConn(ctx context.Context, addr string) (redis.Conn, error) {
+	if addr == "" {
+		return nil, ErrNoHosts
+	}
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, errClusterPoolClosed
+	}
+	pool, found := p.pools[addr]
+	if !found {
+		pool = p.opts.newPool(addr)
+		p.pools[addr] = pool
+	}
+	p.mu.Unlock()
+
+	conn, err := pool.GetContext(ctx)
+	if err == nil {
+		err = conn.Err()
+	}
+	if err != nil {
+		if conn != nil {
+			conn.Close()
+		}
+		if err != redis.ErrPoolExhausted && ctx.Err() == nil {
+			go p.refresh(false)
+		}
+		return nil, err
+	}
+	return conn, nil
+}
+
+// hashSlot returns the cluster slot of a key. If the key contains a non empty {hash tag},
+// only the tag is hashed, so that related keys can be placed in the same slot
+func hashSlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key) % clusterSlots)
+}
+
+// crc16 is the CRC16-CCITT (XMODEM) checksum used by redis cluster
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for b := 0; b < 8; b++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// commandKey returns the key a command is routed by. FT.* commands other than the suggestion
+// commands are handled by the coordinator on any node, and have no routing key
+func commandKey(cmd string, args []interface{}) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+	cmd = strings.ToUpper(cmd)
+	if strings.HasPrefix(cmd, "FT.") && !strings.HasPrefix(cmd, "FT.SUG") {
+		return "", false
+	}
+	switch cmd {
+	case "ASKING", "AUTH", "CLIENT", "CLUSTER", "CONFIG", "ECHO", "INFO", "PING", "SELECT":
+		return "", false
+	}
+	switch k := args[0].(type) {
+	case string:
+		return k, true
+	case []byte:
+		return string(k), true
+	default:
+		return fmt.Sprint(k), true
+	}
+}
+
+// clusterRedirect is a MOVED or ASK reply
+type clusterRedirect struct {
+	ask  bool
+	slot int
+	addr string
+}
+
+// parseRedirect returns the redirect carried by a command error, if any
+func parseRedirect(err error) (clusterRedirect, bool) {
+	rerr, ok := err.(redis.Error)
+	if !ok {
+		return clusterRedirect{}, false
+	}
+	parts := strings.Fields(string(rerr))
+	if len(parts) != 3 || (parts[0] != "MOVED" && parts[0] != "ASK") {
+		return clusterRedirect{}, false
+	}
+	slot, e := strconv.Atoi(parts[1])
+	if e != nil {
+		return clusterRedirect{}, false
+	}
+	return clusterRedirect{ask: parts[0] == "ASK", slot: slot, addr: parts[2]}, true
+}
+
+// clusterConn routes the commands of a ClusterPool connection to the cluster's nodes
+type clusterConn struct {
+	pool *ClusterPool
+	ctx  context.Context
+	// conn is the node connection a pipeline started with Send is bound to
+	conn redis.Conn
+}
+
+func (c *clusterConn) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+func (c *clusterConn) Err() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Err()
+}
+
+func (c *clusterConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	return c.do(cmd, args, func(conn redis.Conn) (interface{}, error) {
+		return conn.Do(cmd, args...)
+	})
+}
+
+func (c *clusterConn) DoWithTimeout(timeout time.Duration, cmd string, args ...interface{}) (interface{}, error) {
+	return c.do(cmd, args, func(conn redis.Conn) (interface{}, error) {
+		return redis.DoWithTimeout(conn, timeout, cmd, args...)
+	})
+}
+
+// do executes a command on the node owning it, following redirects
+func (c *clusterConn) do(cmd string, args []interface{}, exec func(redis.Conn) (interface{}, error)) (interface{}, error) {
+	if c.conn != nil {
+		// Do completes the pipeline in progress, on its node
+		reply, err := exec(c.conn)
+		c.checkRedirect(err)
+		return reply, err
+	}
+	if cmd == "" {
+		return nil, nil
+	}
+
+	addr := c.pool.nodeFor(cmd, args)
+	asking := false
+	for redirects := 0; ; redirects++ {
+		conn, err := c.pool.nodeConn(c.ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+		if asking {
+			if _, err = conn.Do("ASKING"); err != nil {
+				conn.Close()
+				return nil, err
+			}
+		}
+		reply, err := exec(conn)
+		conn.Close()
+
+		r, ok := parseRedirect(err)
+		if !ok || redirects >= maxClusterRedirects {
+			if _, isReply := err.(redis.Error); err != nil && !isReply {
+				go c.pool.refresh(false)
+			}
+			return reply, err
+		}
+		if !r.ask {
+			c.pool.moved(r.slot, r.addr)
+		}
+		addr, asking = r.addr, r.ask
+	}
+}
+
+func (c *clusterConn) checkRedirect(err error) {
+	if r, ok := parseRedirect(err); ok && !r.ask {
+		c.pool.moved(r.slot, r.addr)
+	}
+}
+
+// Send queues a command on the node the first command of the pipeline is routed to
+func (c *clusterConn) Send(cmd string, args ...interface{}) error {
+	if c.conn == nil {
+		conn, err := c.pool.nodeConn(c.ctx, c.pool.nodeFor(cmd, args))
+		if err != nil {
+			return err
+		}
+		c.conn = conn
+	}
+	return c.conn.Send(cmd, args...)
+}
+
+func (c *clusterConn) Flush() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Flush()
+}
+
+func (c *clusterConn) Receive() (interface{}, error) {
+	if c.conn == nil {
+		return nil, errors.New("No pending commands")
+	}
+	reply, err := c.conn.Receive()
+	c.checkRedirect(err)
+	return reply, err
+}
+
+func (c *clusterConn) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	if c.conn == nil {
+		return nil, errors.New("No pending commands")
+	}
+	reply, err := redis.ReceiveWithTimeout(c.conn, timeout)
+	c.checkRedirect(err)
+	return reply, err
+}
@@ -0,0 +1,183 @@
+package redisearch
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveReplies accepts connections until the listener is closed, and answers every command
+// with the RESP reply returned by reply, given the command and the previous one on the connection
+func serveReplies(l net.Listener, reply func(args, prev []string) string) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			var prev []string
+			for {
+				args, err := readCommand(r)
+				if err != nil {
+					return
+				}
+				conn.Write([]byte(reply(args, prev)))
+				prev = args
+			}
+		}()
+	}
+}
+
+func TestHashSlot(t *testing.T) {
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{"123456789", 0x31C3 % clusterSlots},
+		{"foo", 12182},
+		{"{user1000}.following", hashSlot("user1000")},
+		{"{user1000}.followers", hashSlot("user1000")},
+		{"foo{}{bar}", hashSlot("foo{}{bar}")},
+		{"foo{{bar}}zap", hashSlot("{bar")},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, hashSlot(tt.key), tt.key)
+	}
+	assert.NotEqual(t, hashSlot("foo{}{bar}"), hashSlot("bar"))
+}
+
+func TestParseClusterSlots(t *testing.T) {
+	reply := []interface{}{
+		[]interface{}{int64(0), int64(5460), []interface{}{[]byte("10.0.0.1"), int64(7000), []byte("id1")},
+			[]interface{}{[]byte("10.0.0.4"), int64(7003)}},
+		[]interface{}{int64(5461), int64(16383), []interface{}{[]byte(""), int64(7001)}},
+	}
+	slots, masters, err := parseClusterSlots(reply, "10.0.0.2:7001")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.0.0.1:7000", "10.0.0.2:7001"}, masters)
+	assert.Equal(t, "10.0.0.1:7000", slots[0])
+	assert.Equal(t, "10.0.0.1:7000", slots[5460])
+	assert.Equal(t, "10.0.0.2:7001", slots[5461])
+	assert.Equal(t, "10.0.0.2:7001", slots[clusterSlots-1])
+
+	_, _, err = parseClusterSlots([]interface{}{[]interface{}{int64(0), int64(clusterSlots)}}, "")
+	assert.NotNil(t, err)
+	_, _, err = parseClusterSlots([]interface{}{[]interface{}{int64(0), int64(clusterSlots), []interface{}{}}}, "")
+	assert.NotNil(t, err)
+}
+
+func TestCommandKey(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		args []interface{}
+		key  string
+		ok   bool
+	}{
+		{"FT.SEARCH", []interface{}{"idx", "hello"}, "", false},
+		{"FT.SUGADD", []interface{}{"ac", "hello", 1}, "ac", true},
+		{"ft.sugget", []interface{}{[]byte("ac"), "he"}, "ac", true},
+		{"HGETALL", []interface{}{"doc1"}, "doc1", true},
+		{"CLUSTER", []interface{}{"SLOTS"}, "", false},
+		{"PING", nil, "", false},
+	}
+	for _, tt := range tests {
+		key, ok := commandKey(tt.cmd, tt.args)
+		assert.Equal(t, tt.key, key, tt.cmd)
+		assert.Equal(t, tt.ok, ok, tt.cmd)
+	}
+}
+
+func TestClusterPoolRedirects(t *testing.T) {
+	a, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("cannot listen: ", err)
+	}
+	defer a.Close()
+	b, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("cannot listen: ", err)
+	}
+	defer b.Close()
+
+	slotsReply := func(addr string) string {
+		host, port, _ := net.SplitHostPort(addr)
+		return fmt.Sprintf("*1\r\n*3\r\n:0\r\n:%d\r\n*2\r\n$%d\r\n%s\r\n:%s\r\n", clusterSlots-1, len(host), host, port)
+	}
+	// all the slots are on a, until they are migrated to b
+	var migrated int32
+	topology := func() string {
+		if atomic.LoadInt32(&migrated) == 1 {
+			return slotsReply(b.Addr().String())
+		}
+		return slotsReply(a.Addr().String())
+	}
+	askSlot := strconv.Itoa(hashSlot("ask"))
+
+	go serveReplies(a, func(args, prev []string) string {
+		switch {
+		case args[0] == "CLUSTER":
+			return topology()
+		case args[0] == "GET" && args[1] == "ask":
+			return "-ASK " + askSlot + " " + b.Addr().String() + "\r\n"
+		case args[0] == "GET" && atomic.LoadInt32(&migrated) == 1:
+			return fmt.Sprintf("-MOVED %d %s\r\n", hashSlot(args[1]), b.Addr().String())
+		}
+		return "$1\r\na\r\n"
+	})
+	go serveReplies(b, func(args, prev []string) string {
+		switch {
+		case args[0] == "CLUSTER":
+			return topology()
+		case args[0] == "GET" && args[1] == "ask" && (len(prev) == 0 || prev[0] != "ASKING"):
+			return "-MOVED " + askSlot + " " + a.Addr().String() + "\r\n"
+		case args[0] == "ASKING":
+			return "+OK\r\n"
+		}
+		return "$1\r\nb\r\n"
+	})
+
+	p := NewClusterPool([]string{a.Addr().String()}, DefaultClientOptions)
+	defer p.Close()
+	conn := p.Get()
+	defer conn.Close()
+
+	v, err := redis.String(conn.Do("GET", "foo"))
+	assert.Nil(t, err)
+	assert.Equal(t, "a", v)
+	assert.Equal(t, []string{a.Addr().String()}, p.Masters())
+
+	// ASK redirects a single command, without changing the slot map
+	v, err = redis.String(conn.Do("GET", "ask"))
+	assert.Nil(t, err)
+	assert.Equal(t, "b", v)
+	assert.Equal(t, []string{a.Addr().String()}, p.Masters())
+
+	atomic.StoreInt32(&migrated, 1)
+	v, err = redis.String(conn.Do("GET", "foo"))
+	assert.Nil(t, err)
+	assert.Equal(t, "b", v)
+
+	assert.Nil(t, p.Refresh())
+	assert.Equal(t, []string{b.Addr().String()}, p.Masters())
+	v, err = redis.String(conn.Do("GET", "bar"))
+	assert.Nil(t, err)
+	assert.Equal(t, "b", v)
+
+	// pipelines are bound to the node of their first command
+	assert.Nil(t, conn.Send("FT.ADD", "idx", "doc1", 1.0))
+	assert.Nil(t, conn.Send("GET", "foo"))
+	assert.Nil(t, conn.Flush())
+	for n := 0; n < 2; n++ {
+		v, err = redis.String(conn.Receive())
+		assert.Nil(t, err)
+		assert.Equal(t, "b", v)
+	}
+}
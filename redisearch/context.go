@@ -0,0 +1,82 @@
+package redisearch
+
+import (
+	"context"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// contextPool is implemented by pools that can give up waiting for a connection when a context is done
+type contextPool interface {
+	GetContext(ctx context.Context) (redis.Conn, error)
+}
+
+// getConn gets a connection from the pool, honoring the context's cancellation and deadline.
+// The returned connection applies the context's deadline to every reply it waits for, and
+// converts the server's error replies to *ServerError
+func getConn(ctx context.Context, pool ConnPool) (redis.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var conn redis.Conn
+	if cp, ok := pool.(contextPool); ok {
+		var err error
+		if conn, err = cp.GetContext(ctx); err != nil {
+			return nil, err
+		}
+	} else {
+		conn = pool.Get()
+	}
+
+	return &ctxConn{Conn: conn, ctx: ctx}, nil
+}
+
+// ctxConn is a connection that checks its context before every Do, bounds the time
+// spent waiting for replies by the context's deadline, and classifies error replies
+type ctxConn struct {
+	redis.Conn
+	ctx context.Context
+}
+
+// timeout returns the time left until the context's deadline, if it has one.
+// An expired deadline yields the smallest timeout, so that waiting for a reply fails
+// right away and the connection is discarded instead of being drained
+func (c *ctxConn) timeout() (time.Duration, bool) {
+	deadline, ok := c.ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	if d := time.Until(deadline); d > 0 {
+		return d, true
+	}
+	return time.Nanosecond, true
+}
+
+// Do fails without sending the command if the context is already done
+func (c *ctxConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+	if cwt, ok := c.Conn.(redis.ConnWithTimeout); ok {
+		if d, hasDeadline := c.timeout(); hasDeadline {
+			reply, err := cwt.DoWithTimeout(d, commandName, args...)
+			return reply, classifyError(err)
+		}
+	}
+	reply, err := c.Conn.Do(commandName, args...)
+	return reply, classifyError(err)
+}
+
+// Receive waits for a reply of a command that was already sent, until the context's deadline
+func (c *ctxConn) Receive() (interface{}, error) {
+	if cwt, ok := c.Conn.(redis.ConnWithTimeout); ok {
+		if d, hasDeadline := c.timeout(); hasDeadline {
+			reply, err := cwt.ReceiveWithTimeout(d)
+			return reply, classifyError(err)
+		}
+	}
+	reply, err := c.Conn.Receive()
+	return reply, classifyError(err)
+}
@@ -0,0 +1,49 @@
+package redisearch
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextCanceled(t *testing.T) {
+	c := &Client{pool: &fakePool{reply: func(string) error { return nil }}, name: "idx"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := c.SearchContext(ctx, NewQuery("hello"))
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, context.Canceled, c.DropContext(ctx))
+}
+
+func TestIndexWithResultsContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pool := &fakePool{reply: func(id string) error {
+		if id == "doc50" {
+			cancel()
+		}
+		return nil
+	}}
+	c := &Client{pool: pool, name: "idx"}
+
+	docs := make([]Document, 250)
+	for i := range docs {
+		docs[i] = NewDocument(fmt.Sprintf("doc%d", i), 1)
+	}
+	results, err := c.IndexWithResultsContext(ctx, DefaultIndexingOptions, docs...)
+	assert.Equal(t, context.Canceled, err)
+
+	// the first pipeline completes, the rest is never sent
+	assert.Equal(t, contextPipelineSize, len(pool.sent))
+	for i, res := range results {
+		if i < contextPipelineSize {
+			assert.Nil(t, res.Err)
+		} else {
+			assert.Equal(t, context.Canceled, res.Err)
+		}
+	}
+}
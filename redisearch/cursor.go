@@ -0,0 +1,196 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// CursorOptions control how an aggregation cursor reads its results
+type CursorOptions struct {
+	// Count is the number of rows read in each batch. Zero uses the server default
+	Count int
+	// MaxIdle is the time the server keeps an unread cursor alive. Zero uses the server default
+	MaxIdle time.Duration
+}
+
+// DefaultCursorOptions are the default options for aggregation cursors
+var DefaultCursorOptions = CursorOptions{
+	Count:   1000,
+	MaxIdle: 0,
+}
+
+// AggregateCursor iterates over the results of an aggregation in batches, using a server side cursor.
+// The cursor holds a connection until it is exhausted or closed, so Close must always be called:
+//
+//	cur := c.AggregateCursor(q, DefaultCursorOptions)
+//	defer cur.Close()
+//	for cur.Next() {
+//		for _, row := range cur.Rows() {
+//			...
+//		}
+//	}
+//	if err := cur.Err(); err != nil {
+//		...
+//	}
+type AggregateCursor struct {
+	ctx    context.Context
+	client *Client
+	query  *AggregateQuery
+	opts   CursorOptions
+
+	conn    redis.Conn
+	id      int64
+	started bool
+	done    bool
+	rows    []AggregateRow
+	total   int
+	err     error
+}
+
+// AggregateCursor runs the aggregation with a server side cursor, returning an iterator over its batches.
+// The query is only sent on the first call to Next
+func (i *Client) AggregateCursor(q *AggregateQuery, opts CursorOptions) *AggregateCursor {
+	return i.AggregateCursorContext(context.Background(), q, opts)
+}
+
+// AggregateCursorContext is like AggregateCursor, but every batch read honors the context's
+// cancellation and deadline. The server cursor is deleted on Close even if the context is done
+func (i *Client) AggregateCursorContext(ctx context.Context, q *AggregateQuery, opts CursorOptions) *AggregateCursor {
+	return &AggregateCursor{
+		ctx:    ctx,
+		client: i,
+		query:  q,
+		opts:   opts,
+	}
+}
+
+// Next reads the next batch of rows, returning false when the cursor is exhausted or an error occurred
+func (c *AggregateCursor) Next() bool {
+	if c.done {
+		return false
+	}
+
+	var res interface{}
+	var err error
+	if !c.started {
+		c.started = true
+		if c.conn, err = getConn(c.ctx, c.client.pool); err != nil {
+			c.err = err
+			c.finish()
+			return false
+		}
+		args := redis.Args{c.client.name}
+		args = append(args, c.query.serialize()...)
+		args = args.Add("WITHCURSOR")
+		if c.opts.Count > 0 {
+			args = args.Add("COUNT", c.opts.Count)
+		}
+		if c.opts.MaxIdle > 0 {
+			args = args.Add("MAXIDLE", int64(c.opts.MaxIdle/time.Millisecond))
+		}
+		res, err = c.conn.Do("FT.AGGREGATE", args...)
+	} else {
+		if c.id == 0 {
+			c.finish()
+			return false
+		}
+		args := redis.Args{"READ", c.client.name, c.id}
+		if c.opts.Count > 0 {
+			args = args.Add("COUNT", c.opts.Count)
+		}
+		res, err = c.conn.Do("FT.CURSOR", args...)
+	}
+
+	if err == nil {
+		err = c.load(res)
+	}
+	if err != nil {
+		c.err = err
+		c.finish()
+		return false
+	}
+	return true
+}
+
+// load parses a [[total, row...], cursor id] reply into the current batch
+func (c *AggregateCursor) load(reply interface{}) error {
+	res, err := redis.Values(reply, nil)
+	if err != nil {
+		return err
+	}
+	if len(res) != 2 {
+		return errors.New("Invalid cursor reply")
+	}
+	if c.id, err = redis.Int64(res[1], nil); err != nil {
+		return err
+	}
+	batch, err := redis.Values(res[0], nil)
+	if err != nil {
+		return err
+	}
+	if len(batch) == 0 {
+		return errors.New("Empty cursor batch")
+	}
+	if total, err := redis.Int(batch[0], nil); err == nil && total > 0 {
+		c.total = total
+	}
+
+	c.rows = make([]AggregateRow, 0, len(batch)-1)
+	for _, r := range batch[1:] {
+		row, err := loadAggregateRow(r)
+		if err != nil {
+			return err
+		}
+		c.rows = append(c.rows, row)
+	}
+	return nil
+}
+
+// Rows returns the rows of the current batch
+func (c *AggregateCursor) Rows() []AggregateRow {
+	return c.rows
+}
+
+// Total returns the total number of results reported by the engine
+func (c *AggregateCursor) Total() int {
+	return c.total
+}
+
+// Err returns the error that stopped the iteration, if any
+func (c *AggregateCursor) Err() error {
+	return c.err
+}
+
+// Close deletes the server side cursor if it was not exhausted, and releases the connection
+func (c *AggregateCursor) Close() error {
+	return c.finish()
+}
+
+// finish deletes the server cursor if it is still open and releases the connection
+func (c *AggregateCursor) finish() (err error) {
+	c.done = true
+	c.rows = nil
+	if c.conn == nil {
+		return nil
+	}
+	if c.id != 0 {
+		conn := c.conn
+		if cc, ok := conn.(*ctxConn); ok {
+			// the cursor must be deleted even if the context is done
+			conn = cc.Conn
+		}
+		if conn.Err() != nil {
+			// the connection broke, e.g. on a read timeout, but the cursor lives on the server
+			conn = c.client.pool.Get()
+			defer conn.Close()
+		}
+		_, err = conn.Do("FT.CURSOR", "DEL", c.client.name, c.id)
+		c.id = 0
+	}
+	c.conn.Close()
+	c.conn = nil
+	return err
+}
@@ -0,0 +1,353 @@
+package redisearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ErrIndexDrift is returned by EnsureIndex when the existing index differs from its definition
+var ErrIndexDrift = errors.New("Index differs from its definition")
+
+// fieldDefinition is the declarative form of a Field, with the options of every type flattened.
+// Options that do not apply to the field's type are rejected
+type fieldDefinition struct {
+	Name      string  `json:"name" yaml:"name"`
+	Type      string  `json:"type" yaml:"type"`
+	Weight    float32 `json:"weight,omitempty" yaml:"weight,omitempty"`
+	Separator string  `json:"separator,omitempty" yaml:"separator,omitempty"`
+	Sortable  bool    `json:"sortable,omitempty" yaml:"sortable,omitempty"`
+	NoStem    bool    `json:"nostem,omitempty" yaml:"nostem,omitempty"`
+	NoIndex   bool    `json:"noindex,omitempty" yaml:"noindex,omitempty"`
+	Phonetic  bool    `json:"phonetic,omitempty" yaml:"phonetic,omitempty"`
+	// PhoneticMatcher implies Phonetic, whose matcher is dm:en by default
+	PhoneticMatcher string `json:"phonetic_matcher,omitempty" yaml:"phonetic_matcher,omitempty"`
+}
+
+// optionsDefinition is the declarative form of Options. Stopwords are a pointer so that the default
+// list, when they are missing, can be told apart from an empty list
+type optionsDefinition struct {
+	NoSave          bool      `json:"nosave,omitempty" yaml:"nosave,omitempty"`
+	NoFieldFlags    bool      `json:"nofields,omitempty" yaml:"nofields,omitempty"`
+	NoFrequencies   bool      `json:"nofreqs,omitempty" yaml:"nofreqs,omitempty"`
+	NoOffsetVectors bool      `json:"nooffsets,omitempty" yaml:"nooffsets,omitempty"`
+	MaxTextFields   bool      `json:"maxtextfields,omitempty" yaml:"maxtextfields,omitempty"`
+	Stopwords       *[]string `json:"stopwords,omitempty" yaml:"stopwords,omitempty"`
+}
+
+// schemaDefinition is the declarative form of a Schema
+type schemaDefinition struct {
+	Options Options `json:"options" yaml:"options"`
+	Fields  []Field `json:"fields" yaml:"fields"`
+}
+
+// fieldError names the field an error is about
+func fieldError(name string, err error) error {
+	if name == "" {
+		return fmt.Errorf("Invalid field: %s", err)
+	}
+	return fmt.Errorf("Field %s: %s", name, err)
+}
+
+// newFieldDefinition returns the declarative form of a field as it is created, see canonicalField,
+// leaving out the default weight and separator
+func newFieldDefinition(f Field) (fieldDefinition, error) {
+	if _, err := serializeField(f); err != nil {
+		return fieldDefinition{}, fieldError(f.Name, err)
+	}
+	def := canonicalField(f)
+	d := fieldDefinition{
+		Name:     f.Name,
+		Type:     fieldTypeNames[f.Type],
+		Sortable: def.Sortable,
+		NoStem:   def.NoStem,
+		NoIndex:  def.NoIndex,
+		Phonetic: def.Phonetic != "",
+	}
+	if def.Phonetic != "dm:en" {
+		d.PhoneticMatcher = def.Phonetic
+	}
+	if f.Type == TextField && def.Weight != 1 {
+		d.Weight = def.Weight
+	}
+	if f.Type == TagField && def.Separator != ',' {
+		d.Separator = string(def.Separator)
+	}
+	return d, nil
+}
+
+// field validates the definition and builds the field, leaving its options nil when they are all
+// defaults, like the constructors do
+func (d fieldDefinition) field() (Field, error) {
+	fail := func(format string, args ...interface{}) (Field, error) {
+		return Field{}, fieldError(d.Name, fmt.Errorf(format, args...))
+	}
+	if d.Name == "" {
+		return fail("missing name")
+	}
+	typ := strings.ToUpper(d.Type)
+	if d.Weight < 0 {
+		return fail("invalid weight %v", d.Weight)
+	}
+	if d.Weight != 0 && typ != "TEXT" {
+		return fail("weight is only valid for TEXT fields")
+	}
+	if (d.NoStem || d.Phonetic || d.PhoneticMatcher != "") && typ != "TEXT" {
+		return fail("nostem and phonetic are only valid for TEXT fields")
+	}
+	if d.Separator != "" && typ != "TAG" {
+		return fail("separator is only valid for TAG fields")
+	}
+	if d.Sortable && typ == "GEO" {
+		return fail("GEO fields cannot be sortable")
+	}
+
+	switch typ {
+	case "TEXT":
+		f := NewTextField(d.Name)
+		weight := d.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		matcher := d.PhoneticMatcher
+		phonetic := d.Phonetic || matcher != ""
+		if matcher == "dm:en" {
+			matcher = ""
+		}
+		if d.Sortable || d.NoStem || d.NoIndex || phonetic || weight != 1 {
+			f.Options = TextFieldOptions{
+				Weight:          weight,
+				Sortable:        d.Sortable,
+				NoStem:          d.NoStem,
+				NoIndex:         d.NoIndex,
+				DMENPhonetic:    phonetic && matcher == "",
+				PhoneticMatcher: matcher,
+			}
+		}
+		return f, nil
+	case "NUMERIC":
+		f := NewNumericField(d.Name)
+		if d.Sortable || d.NoIndex {
+			f.Options = NumericFieldOptions{Sortable: d.Sortable, NoIndex: d.NoIndex}
+		}
+		return f, nil
+	case "TAG":
+		var separator byte = ','
+		if d.Separator != "" {
+			if len(d.Separator) != 1 {
+				return fail("invalid separator %q, must be a single character", d.Separator)
+			}
+			separator = d.Separator[0]
+		}
+		return NewTagFieldOptions(d.Name, TagFieldOptions{
+			Separator: separator,
+			Sortable:  d.Sortable,
+			NoIndex:   d.NoIndex,
+		}), nil
+	case "GEO":
+		f := NewGeoField(d.Name)
+		if d.NoIndex {
+			f.Options = GeoFieldOptions{NoIndex: true}
+		}
+		return f, nil
+	case "":
+		return fail("missing type")
+	}
+	return fail("unsupported type %q", d.Type)
+}
+
+// decodeJSONStrict decodes JSON, rejecting unknown keys so that misspelled options are reported
+func decodeJSONStrict(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// MarshalJSON encodes the field as an object with its name, type and options
+func (f Field) MarshalJSON() ([]byte, error) {
+	d, err := newFieldDefinition(f)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(d)
+}
+
+// UnmarshalJSON decodes and validates a field encoded by MarshalJSON
+func (f *Field) UnmarshalJSON(data []byte) error {
+	var d fieldDefinition
+	if err := decodeJSONStrict(data, &d); err != nil {
+		return fieldError(d.Name, err)
+	}
+	field, err := d.field()
+	if err != nil {
+		return err
+	}
+	*f = field
+	return nil
+}
+
+// MarshalYAML encodes the field as a mapping with its name, type and options
+func (f Field) MarshalYAML() (interface{}, error) {
+	return newFieldDefinition(f)
+}
+
+// UnmarshalYAML decodes and validates a field encoded by MarshalYAML
+func (f *Field) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var d fieldDefinition
+	if err := unmarshal(&d); err != nil {
+		return fieldError(d.Name, err)
+	}
+	field, err := d.field()
+	if err != nil {
+		return err
+	}
+	*f = field
+	return nil
+}
+
+func newOptionsDefinition(o Options) optionsDefinition {
+	d := optionsDefinition{
+		NoSave:          o.NoSave,
+		NoFieldFlags:    o.NoFieldFlags,
+		NoFrequencies:   o.NoFrequencies,
+		NoOffsetVectors: o.NoOffsetVectors,
+		MaxTextFields:   o.MaxTextFields,
+	}
+	if o.Stopwords != nil {
+		d.Stopwords = &o.Stopwords
+	}
+	return d
+}
+
+func (d optionsDefinition) options() Options {
+	o := Options{
+		NoSave:          d.NoSave,
+		NoFieldFlags:    d.NoFieldFlags,
+		NoFrequencies:   d.NoFrequencies,
+		NoOffsetVectors: d.NoOffsetVectors,
+		MaxTextFields:   d.MaxTextFields,
+	}
+	if d.Stopwords != nil {
+		o.Stopwords = *d.Stopwords
+		if o.Stopwords == nil {
+			o.Stopwords = []string{}
+		}
+	}
+	return o
+}
+
+// MarshalJSON encodes the options as an object. Stopwords are left out when the default list is used
+func (o Options) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newOptionsDefinition(o))
+}
+
+// UnmarshalJSON decodes options encoded by MarshalJSON
+func (o *Options) UnmarshalJSON(data []byte) error {
+	var d optionsDefinition
+	if err := decodeJSONStrict(data, &d); err != nil {
+		return fmt.Errorf("Invalid index options: %s", err)
+	}
+	*o = d.options()
+	return nil
+}
+
+// MarshalYAML encodes the options as a mapping. Stopwords are left out when the default list is used
+func (o Options) MarshalYAML() (interface{}, error) {
+	return newOptionsDefinition(o), nil
+}
+
+// UnmarshalYAML decodes options encoded by MarshalYAML
+func (o *Options) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var d optionsDefinition
+	if err := unmarshal(&d); err != nil {
+		return fmt.Errorf("Invalid index options: %s", err)
+	}
+	*o = d.options()
+	return nil
+}
+
+// setDefinition sets the schema from its decoded definition, if it is valid
+func (m *Schema) setDefinition(d schemaDefinition) error {
+	if d.Fields == nil {
+		d.Fields = []Field{}
+	}
+	s := Schema{Fields: d.Fields, Options: d.Options}
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	*m = s
+	return nil
+}
+
+// MarshalJSON encodes the schema as an object with its options and fields
+func (m Schema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(schemaDefinition{Options: m.Options, Fields: m.Fields})
+}
+
+// UnmarshalJSON decodes and validates a schema encoded by MarshalJSON
+func (m *Schema) UnmarshalJSON(data []byte) error {
+	var d schemaDefinition
+	if err := decodeJSONStrict(data, &d); err != nil {
+		return err
+	}
+	return m.setDefinition(d)
+}
+
+// MarshalYAML encodes the schema as a mapping with its options and fields
+func (m Schema) MarshalYAML() (interface{}, error) {
+	return schemaDefinition{Options: m.Options, Fields: m.Fields}, nil
+}
+
+// UnmarshalYAML decodes and validates a schema encoded by MarshalYAML
+func (m *Schema) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var d schemaDefinition
+	if err := unmarshal(&d); err != nil {
+		return err
+	}
+	return m.setDefinition(d)
+}
+
+// ParseSchema decodes a schema definition in YAML or JSON, rejecting unknown keys:
+//
+//	options:
+//	  nofreqs: true
+//	  stopwords: [a, the]
+//	fields:
+//	  - {name: title, type: TEXT, weight: 2, sortable: true}
+//	  - {name: tags, type: TAG, separator: ";"}
+//	  - {name: price, type: NUMERIC, sortable: true}
+//	  - {name: location, type: GEO}
+func ParseSchema(data []byte) (*Schema, error) {
+	s := NewSchema(DefaultOptions)
+	if err := yaml.UnmarshalStrict(data, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// EnsureIndex creates the index from the schema if it does not exist. If the index exists and differs
+// from the schema, it is left unchanged, and the plan to migrate it is returned along with an error
+// wrapping ErrIndexDrift that lists the differences
+func (i *Client) EnsureIndex(s *Schema) (*MigrationPlan, error) {
+	return i.EnsureIndexContext(context.Background(), s)
+}
+
+// EnsureIndexContext is like EnsureIndex, but honors the context's cancellation and deadline
+func (i *Client) EnsureIndexContext(ctx context.Context, s *Schema) (*MigrationPlan, error) {
+	plan, err := i.PlanMigrationContext(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Create {
+		return plan, plan.ExecuteContext(ctx, i)
+	}
+	if !plan.Empty() {
+		return plan, fmt.Errorf("%w: %s", ErrIndexDrift, strings.Join(plan.changes(), "; "))
+	}
+	return plan, nil
+}
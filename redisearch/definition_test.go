@@ -0,0 +1,156 @@
+package redisearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestSchemaDefinition(t *testing.T) {
+	sc := NewSchema(Options{NoFrequencies: true, MaxTextFields: true, Stopwords: []string{}}).
+		AddField(NewTextField("title")).
+		AddField(NewTextFieldOptions("body", TextFieldOptions{Weight: 2.5, NoStem: true, DMENPhonetic: true})).
+		AddField(NewSortableNumericField("price")).
+		AddField(NewTagField("tags")).
+		AddField(NewTagFieldOptions("cats", TagFieldOptions{Separator: ';', Sortable: true, NoIndex: true})).
+		AddField(NewGeoField("location")).
+		AddField(NewGeoFieldOptions("hidden", GeoFieldOptions{NoIndex: true}))
+
+	data, err := yaml.Marshal(sc)
+	assert.Nil(t, err)
+	parsed, err := ParseSchema(data)
+	assert.Nil(t, err)
+	assert.Equal(t, sc, parsed)
+
+	data, err = json.Marshal(sc)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `{"name":"cats","type":"TAG","separator":";","sortable":true,"noindex":true}`)
+	var decoded Schema
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, *sc, decoded)
+	parsed, err = ParseSchema(data)
+	assert.Nil(t, err)
+	assert.Equal(t, sc, parsed)
+
+	parsed, err = ParseSchema([]byte("fields:\n  - {name: title, type: text}\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, parsed.Options.Stopwords)
+	assert.Equal(t, []Field{NewTextField("title")}, parsed.Fields)
+}
+
+func TestSchemaDefinitionFieldSortable(t *testing.T) {
+	// Field.Sortable is not sent to the server, so the field is defined as not sortable
+	sc := NewSchema(DefaultOptions).
+		AddField(Field{Name: "n", Type: NumericField, Sortable: true}).
+		AddField(Field{Name: "title", Type: TextField, Sortable: true, Options: TextFieldOptions{Weight: 2}})
+
+	data, err := json.Marshal(sc)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `[{"name":"n","type":"NUMERIC"},{"name":"title","type":"TEXT","weight":2}]`)
+	var decoded Schema
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	data, err = yaml.Marshal(sc)
+	assert.Nil(t, err)
+	parsed, err := ParseSchema(data)
+	assert.Nil(t, err)
+
+	for i, f := range sc.Fields {
+		want, err := serializeField(f)
+		assert.Nil(t, err)
+		for _, got := range []Field{decoded.Fields[i], parsed.Fields[i]} {
+			args, err := serializeField(got)
+			assert.Nil(t, err)
+			assert.Equal(t, want, args)
+		}
+	}
+}
+
+func TestSchemaDefinitionErrors(t *testing.T) {
+	tests := []struct {
+		def string
+		err string
+	}{
+		{`{name: title}`, "Field title: missing type"},
+		{`{type: TEXT}`, "Invalid field: missing name"},
+		{`{name: title, type: VECTOR}`, `Field title: unsupported type "VECTOR"`},
+		{`{name: tags, type: TAG, weight: 2}`, "Field tags: weight is only valid for TEXT fields"},
+		{`{name: tags, type: TAG, separator: ";;"}`, `Field tags: invalid separator ";;", must be a single character`},
+		{`{name: title, type: TEXT, separator: ";"}`, "Field title: separator is only valid for TAG fields"},
+		{`{name: price, type: NUMERIC, nostem: true}`, "Field price: nostem and phonetic are only valid for TEXT fields"},
+		{`{name: location, type: GEO, sortable: true}`, "Field location: GEO fields cannot be sortable"},
+		{`{name: title, type: TEXT, weight: -1}`, "Field title: invalid weight -1"},
+		{`{name: title, type: TEXT, sortible: true}`, "Field title: "},
+	}
+	for _, tt := range tests {
+		_, err := ParseSchema([]byte("fields:\n  - " + tt.def + "\n"))
+		if assert.NotNil(t, err, tt.def) {
+			assert.Contains(t, err.Error(), tt.err, tt.def)
+		}
+
+		var f Field
+		err = json.Unmarshal([]byte(yamlToJSON(t, tt.def)), &f)
+		if assert.NotNil(t, err, tt.def) {
+			assert.True(t, strings.HasPrefix(err.Error(), tt.err), err.Error())
+		}
+	}
+
+	_, err := ParseSchema([]byte("fields:\n  - {name: title, type: TEXT}\n  - {name: title, type: TAG}\n"))
+	assert.EqualError(t, err, "[0] Field title: defined twice\n")
+	_, err = ParseSchema([]byte("options: {nofreq: true}\n"))
+	assert.NotNil(t, err)
+}
+
+// yamlToJSON converts a flow mapping to JSON
+func yamlToJSON(t *testing.T, def string) string {
+	var m map[string]interface{}
+	assert.Nil(t, yaml.Unmarshal([]byte(def), &m))
+	data, err := json.Marshal(m)
+	assert.Nil(t, err)
+	return string(data)
+}
+
+func TestEnsureIndex(t *testing.T) {
+	var fields []interface{}
+	var commands []string
+	c := NewClientFromExecutor(FuncExecutor{DoFunc: func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		commands = append(commands, args[0].(string))
+		switch args[0] {
+		case "FT.INFO":
+			if fields == nil {
+				return nil, redis.Error("Unknown Index name")
+			}
+			return []interface{}{"index_name", "idx", "fields", fields}, nil
+		}
+		return "OK", nil
+	}}, "idx")
+
+	sc, err := ParseSchema([]byte("fields:\n  - {name: title, type: TEXT}\n  - {name: price, type: NUMERIC}\n"))
+	assert.Nil(t, err)
+
+	plan, err := c.EnsureIndex(sc)
+	assert.Nil(t, err)
+	assert.True(t, plan.Create)
+	assert.Equal(t, []string{"FT.INFO", "FT.CREATE"}, commands)
+
+	commands = nil
+	fields = []interface{}{
+		[]interface{}{"title", "type", "TEXT", "WEIGHT", "1"},
+		[]interface{}{"price", "type", "NUMERIC"},
+	}
+	plan, err = c.EnsureIndex(sc)
+	assert.Nil(t, err)
+	assert.True(t, plan.Empty())
+
+	fields = []interface{}{[]interface{}{"title", "type", "TAG", "SEPARATOR", ","}}
+	plan, err = c.EnsureIndex(sc)
+	assert.True(t, errors.Is(err, ErrIndexDrift))
+	assert.Equal(t, "Index differs from its definition: add field price; rebuild: field title changes type from TAG to TEXT", err.Error())
+	assert.True(t, plan.NeedsRebuild())
+	assert.Equal(t, []string{"FT.INFO", "FT.INFO"}, commands)
+}
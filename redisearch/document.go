@@ -2,6 +2,7 @@ package redisearch
 
 import (
 	"sort"
+	"strconv"
 )
 
 // Document represents a single document to be indexed or returned from a query.
@@ -33,6 +34,11 @@ func (d Document) Set(name string, value interface{}) Document {
 	return d
 }
 
+// SetGeo sets a geo property to the given point, formatted as "lon,lat"
+func (d Document) SetGeo(name string, lon, lat float64) Document {
+	return d.Set(name, strconv.FormatFloat(lon, 'f', -1, 64)+","+strconv.FormatFloat(lat, 'f', -1, 64))
+}
+
 // DocumentList is used to sort documents by descending score
 type DocumentList []Document
 
@@ -0,0 +1,125 @@
+package redisearch
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// Errors returned by the server, classified by kind. Use errors.Is to test for them, and errors.As
+// with a *ServerError to get the server's message and details
+var (
+	ErrIndexNotFound  = errors.New("Index not found")
+	ErrIndexExists    = errors.New("Index already exists")
+	ErrDocumentExists = errors.New("Document already exists")
+	ErrUnknownField   = errors.New("Unknown field")
+	ErrFieldExists    = errors.New("Field already exists")
+	ErrSyntax         = errors.New("Syntax error")
+	ErrTimeout        = errors.New("Timeout")
+	ErrOutOfMemory    = errors.New("Out of memory")
+)
+
+// ServerError is an error reply of the server
+type ServerError struct {
+	// Kind is one of the Err* errors of this package, or nil if the error was not recognized
+	Kind error
+	// Msg is the server's message
+	Msg string
+	// Pos is the offset of a syntax error in the query, or -1 if unknown
+	Pos int
+	// Near is the part of the query, or the field name, the server reported the error at
+	Near string
+}
+
+// Error returns the server's message
+func (e *ServerError) Error() string {
+	return e.Msg
+}
+
+// Is matches the error's kind
+func (e *ServerError) Is(target error) bool {
+	return e.Kind != nil && e.Kind == target
+}
+
+// Unwrap returns the original reply error, so that errors.As also matches a redis.Error
+func (e *ServerError) Unwrap() error {
+	return redis.Error(e.Msg)
+}
+
+// serverErrorKinds maps the lower case fragments of server messages to their kind
+var serverErrorKinds = []struct {
+	fragment string
+	kind     error
+}{
+	{"unknown index name", ErrIndexNotFound},
+	{"no such index", ErrIndexNotFound},
+	{"index already exists", ErrIndexExists},
+	{"document already exists", ErrDocumentExists},
+	{"document already in index", ErrDocumentExists},
+	{"unknown field", ErrUnknownField},
+	{"not loaded nor in schema", ErrUnknownField},
+	{"duplicate field in schema", ErrFieldExists},
+	{"syntax error", ErrSyntax},
+	{"timeout limit was reached", ErrTimeout},
+	{"oom ", ErrOutOfMemory},
+	{"out of memory", ErrOutOfMemory},
+}
+
+// classifyError converts the error replies of the server to a *ServerError. Other errors,
+// such as network and context errors, are returned unchanged
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var msg string
+	switch e := err.(type) {
+	case *ServerError:
+		return e
+	case redis.Error:
+		msg = string(e)
+	default:
+		return err
+	}
+
+	ret := &ServerError{Msg: msg, Pos: -1}
+	lower := strings.ToLower(msg) + " "
+	for _, k := range serverErrorKinds {
+		if strings.Contains(lower, k.fragment) {
+			ret.Kind = k.kind
+			break
+		}
+	}
+	if ret.Kind == ErrSyntax || ret.Kind == ErrUnknownField {
+		ret.Pos, ret.Near = errorLocation(msg)
+	}
+	return ret
+}
+
+// errorLocation extracts the offset and the text reported in messages such as
+// "Syntax error at offset 4 near foo", or the quoted name in "Unknown field `foo`"
+func errorLocation(msg string) (pos int, near string) {
+	pos = -1
+	fields := strings.Fields(msg)
+	for i := 0; i+1 < len(fields); i++ {
+		switch fields[i] {
+		case "offset":
+			if n, err := strconv.Atoi(fields[i+1]); err == nil {
+				pos = n
+			}
+		case "near":
+			near = strings.Join(fields[i+1:], " ")
+		}
+	}
+	if near == "" {
+		for _, quote := range []string{"`", "'", "\""} {
+			if start := strings.Index(msg, quote); start >= 0 {
+				if end := strings.Index(msg[start+1:], quote); end >= 0 {
+					return pos, msg[start+1 : start+1+end]
+				}
+			}
+		}
+	}
+	return pos, near
+}
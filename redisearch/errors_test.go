@@ -0,0 +1,51 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		kind error
+		pos  int
+		near string
+	}{
+		{redis.Error("Unknown Index name"), ErrIndexNotFound, -1, ""},
+		{redis.Error("idx: no such index"), ErrIndexNotFound, -1, ""},
+		{redis.Error("Index already exists. Drop it first!"), ErrIndexExists, -1, ""},
+		{redis.Error("Document already exists"), ErrDocumentExists, -1, ""},
+		{redis.Error("Unknown field at offset 0 near title"), ErrUnknownField, 0, "title"},
+		{redis.Error("Property `price` not loaded nor in schema"), ErrUnknownField, -1, "price"},
+		{redis.Error("Duplicate field in schema - title"), ErrFieldExists, -1, ""},
+		{redis.Error("Syntax error at offset 4 near ello"), ErrSyntax, 4, "ello"},
+		{redis.Error("Timeout limit was reached"), ErrTimeout, -1, ""},
+		{redis.Error("OOM command not allowed when used memory > 'maxmemory'."), ErrOutOfMemory, -1, ""},
+		{redis.Error("ERR something else"), nil, -1, ""},
+	}
+	for _, tt := range tests {
+		err := classifyError(tt.err)
+		var serr *ServerError
+		assert.True(t, errors.As(err, &serr), tt.err.Error())
+		assert.Equal(t, tt.kind, serr.Kind, tt.err.Error())
+		assert.Equal(t, tt.pos, serr.Pos, tt.err.Error())
+		assert.Equal(t, tt.near, serr.Near, tt.err.Error())
+		assert.Equal(t, tt.err.Error(), err.Error())
+		if tt.kind != nil {
+			assert.True(t, errors.Is(err, tt.kind))
+			assert.True(t, errors.Is(&DocumentError{Id: "doc", Err: err}, tt.kind))
+		}
+
+		var rerr redis.Error
+		assert.True(t, errors.As(err, &rerr))
+		assert.Equal(t, err, classifyError(err))
+	}
+
+	assert.Nil(t, classifyError(nil))
+	assert.Equal(t, context.Canceled, classifyError(context.Canceled))
+}
@@ -0,0 +1,266 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// Command is a redis command and its arguments
+type Command struct {
+	Name string
+	Args []interface{}
+}
+
+// Reply is the outcome of a single command of a pipeline
+type Reply struct {
+	Value interface{}
+	Err   error
+}
+
+// Executor runs redis commands independently of the driver. It allows a Client or an Autocompleter
+// to share the connections of an application, through NewClientFromExecutor and NewAutocompleterFromExecutor.
+//
+// Replies are expected in the shape returned by redis drivers: nil, int64, string or []byte for
+// simple and bulk strings, and []interface{} for arrays
+type Executor interface {
+	// Do runs a single command and returns its reply
+	Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error)
+	// Pipeline runs the commands in as few round trips as possible, and returns the reply of every
+	// command in order. The error is set when the pipeline as a whole failed
+	Pipeline(ctx context.Context, cmds []Command) ([]Reply, error)
+}
+
+// NewPoolExecutor creates an executor running the commands on connections from a redigo pool
+func NewPoolExecutor(pool ConnPool) Executor {
+	return poolExecutor{pool}
+}
+
+type poolExecutor struct {
+	pool ConnPool
+}
+
+func (e poolExecutor) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	conn, err := getConn(ctx, e.pool)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.Do(cmd, args...)
+}
+
+func (e poolExecutor) Pipeline(ctx context.Context, cmds []Command) ([]Reply, error) {
+	conn, err := getConn(ctx, e.pool)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	for _, cmd := range cmds {
+		if err := conn.Send(cmd.Name, cmd.Args...); err != nil {
+			return nil, err
+		}
+	}
+	if err := conn.Flush(); err != nil {
+		return nil, err
+	}
+	replies := make([]Reply, len(cmds))
+	for i := range replies {
+		replies[i].Value, replies[i].Err = conn.Receive()
+		var rerr redis.Error
+		if replies[i].Err != nil && !errors.As(replies[i].Err, &rerr) {
+			return replies[:i], replies[i].Err
+		}
+	}
+	return replies, nil
+}
+
+// FuncExecutor is an executor calling functions, allowing the use of any driver without depending on it.
+// Replies are expected in the shape redigo returns them: a nil reply is a nil value rather than an
+// error, and error replies are a redis.Error, so that they are told apart from network errors.
+// The goredis package provides an executor for go-redis
+type FuncExecutor struct {
+	// DoFunc runs a single command. The command name is the first argument.
+	// A nil reply must be returned as a nil value rather than an error
+	DoFunc func(ctx context.Context, args ...interface{}) (interface{}, error)
+	// PipelineFunc runs several commands, each given as its name followed by its arguments.
+	// If nil, the commands are run one after the other with DoFunc
+	PipelineFunc func(ctx context.Context, cmds [][]interface{}) ([]Reply, error)
+}
+
+func commandArgs(cmd string, args []interface{}) []interface{} {
+	return append([]interface{}{cmd}, args...)
+}
+
+func (e FuncExecutor) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	return e.DoFunc(ctx, commandArgs(cmd, args)...)
+}
+
+func (e FuncExecutor) Pipeline(ctx context.Context, cmds []Command) ([]Reply, error) {
+	if e.PipelineFunc != nil {
+		all := make([][]interface{}, len(cmds))
+		for i, cmd := range cmds {
+			all[i] = commandArgs(cmd.Name, cmd.Args)
+		}
+		return e.PipelineFunc(ctx, all)
+	}
+
+	replies := make([]Reply, len(cmds))
+	for i, cmd := range cmds {
+		if err := ctx.Err(); err != nil {
+			return replies[:i], err
+		}
+		replies[i].Value, replies[i].Err = e.Do(ctx, cmd.Name, cmd.Args...)
+	}
+	return replies, nil
+}
+
+// NewExecutorPool creates a pool whose connections run their commands with the executor.
+// Commands queued with Send are run as a single pipeline on Flush
+func NewExecutorPool(e Executor) ConnPool {
+	return executorPool{e}
+}
+
+// NewClientFromExecutor creates a new client running its commands on the index name with the executor
+func NewClientFromExecutor(e Executor, name string) *Client {
+	return NewClientFromPool(NewExecutorPool(e), name)
+}
+
+type executorPool struct {
+	exec Executor
+}
+
+func (p executorPool) Get() redis.Conn {
+	return &executorConn{exec: p.exec, ctx: context.Background()}
+}
+
+// GetContext returns a connection running its commands with the context
+func (p executorPool) GetContext(ctx context.Context) (redis.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &executorConn{exec: p.exec, ctx: ctx}, nil
+}
+
+// Close does nothing, the executor's connections belong to its owner
+func (p executorPool) Close() error {
+	return nil
+}
+
+var errNoPendingReply = errors.New("No pending reply")
+
+// executorConn adapts an executor to the redigo connection interface
+type executorConn struct {
+	exec    Executor
+	ctx     context.Context
+	pending []Command
+	replies []Reply
+	err     error
+}
+
+func (c *executorConn) Close() error {
+	c.pending, c.replies = nil, nil
+	return nil
+}
+
+func (c *executorConn) Err() error {
+	return c.err
+}
+
+// Do runs the command. If commands are pending, they are run along with it in a pipeline,
+// and the first error among their replies is returned, as redigo does
+func (c *executorConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	if len(c.pending) == 0 {
+		if cmd == "" {
+			return nil, nil
+		}
+		reply, err := c.exec.Do(c.ctx, cmd, args...)
+		return normalizeReply(reply), c.checkErr(err)
+	}
+
+	if cmd != "" {
+		c.pending = append(c.pending, Command{Name: cmd, Args: args})
+	}
+	if err := c.Flush(); err != nil {
+		return nil, err
+	}
+	replies := c.replies
+	c.replies = nil
+	var last interface{}
+	for _, r := range replies {
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		last = r.Value
+	}
+	if cmd == "" {
+		return nil, nil
+	}
+	return last, nil
+}
+
+func (c *executorConn) Send(cmd string, args ...interface{}) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.pending = append(c.pending, Command{Name: cmd, Args: args})
+	return nil
+}
+
+func (c *executorConn) Flush() error {
+	if len(c.pending) == 0 {
+		return c.err
+	}
+	cmds := c.pending
+	c.pending = nil
+	replies, err := c.exec.Pipeline(c.ctx, cmds)
+	for i := range replies {
+		replies[i].Value = normalizeReply(replies[i].Value)
+	}
+	c.replies = append(c.replies, replies...)
+	if err != nil {
+		// the commands without a reply fail with the pipeline's error
+		for i := len(replies); i < len(cmds); i++ {
+			c.replies = append(c.replies, Reply{Err: err})
+		}
+	}
+	return c.checkErr(err)
+}
+
+func (c *executorConn) Receive() (interface{}, error) {
+	if len(c.replies) == 0 {
+		if err := c.Flush(); err != nil || len(c.replies) == 0 {
+			if err == nil {
+				err = errNoPendingReply
+			}
+			return nil, err
+		}
+	}
+	r := c.replies[0]
+	c.replies = c.replies[1:]
+	return r.Value, r.Err
+}
+
+// checkErr marks the connection as broken after a context error, like redigo does after network errors
+func (c *executorConn) checkErr(err error) error {
+	if err != nil && c.ctx.Err() != nil {
+		c.err = err
+	}
+	return err
+}
+
+// normalizeReply converts string replies to []byte, the shape redigo returns and the client parses
+func normalizeReply(v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		return []byte(val)
+	case []interface{}:
+		for i := range val {
+			val[i] = normalizeReply(val[i])
+		}
+		return val
+	default:
+		return val
+	}
+}
@@ -0,0 +1,89 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingExecutor answers like go-redis does, with string replies, and records the commands it runs
+type recordingExecutor struct {
+	commands []string
+	replies  map[string]interface{}
+}
+
+func (e *recordingExecutor) exec() FuncExecutor {
+	return FuncExecutor{DoFunc: func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		cmd := args[0].(string)
+		e.commands = append(e.commands, cmd)
+		if reply, ok := e.replies[cmd]; ok {
+			if err, isErr := reply.(error); isErr {
+				return nil, err
+			}
+			return reply, nil
+		}
+		return "OK", nil
+	}}
+}
+
+func TestExecutorClient(t *testing.T) {
+	e := &recordingExecutor{replies: map[string]interface{}{
+		"FT.SEARCH": []interface{}{int64(2), "doc1", []interface{}{"title", "hello"}, "doc2", []interface{}{"title", "world"}},
+		"FT.SUGGET": []interface{}{"hello", "help"},
+	}}
+	c := NewClientFromExecutor(e.exec(), "idx")
+
+	docs, total, err := c.Search(NewQuery("hel*"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, 2, len(docs))
+	assert.Equal(t, "doc2", docs[1].Id)
+	assert.Equal(t, "world", docs[1].Properties["title"])
+
+	assert.Nil(t, c.Index(NewDocument("doc1", 1).Set("title", "hello"), NewDocument("doc2", 1)))
+
+	a := NewAutocompleterFromExecutor(e.exec(), "ac")
+	assert.Nil(t, a.AddTerms(Suggestion{Term: "hello", Score: 1}))
+	sugs, err := a.SuggestOpts("hel", SuggestOptions{Num: 5})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(sugs))
+	assert.Equal(t, "help", sugs[1].Term)
+
+	assert.Equal(t, "FT.SEARCH FT.ADD FT.ADD FT.SUGADD FT.SUGGET", strings.Join(e.commands, " "))
+}
+
+func TestExecutorConnPipeline(t *testing.T) {
+	failure := errors.New("failure")
+	e := &recordingExecutor{replies: map[string]interface{}{"GET": "v", "FAIL": failure}}
+	conn := NewExecutorPool(e.exec()).Get()
+	defer conn.Close()
+
+	assert.Nil(t, conn.Send("SET", "k", "v"))
+	assert.Nil(t, conn.Send("FAIL"))
+	assert.Nil(t, conn.Flush())
+	v, err := redis.String(conn.Receive())
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", v)
+	_, err = conn.Receive()
+	assert.Equal(t, failure, err)
+	_, err = conn.Receive()
+	assert.Equal(t, errNoPendingReply, err)
+
+	// Do runs the pending commands along with its own, and fails on the first error
+	assert.Nil(t, conn.Send("SET", "k", "v"))
+	v, err = redis.String(conn.Do("GET", "k"))
+	assert.Nil(t, err)
+	assert.Equal(t, "v", v)
+	assert.Nil(t, conn.Send("FAIL"))
+	_, err = conn.Do("GET", "k")
+	assert.Equal(t, failure, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = NewPoolExecutor(NewExecutorPool(e.exec())).Do(ctx, "GET", "k")
+	assert.Equal(t, context.Canceled, err)
+}
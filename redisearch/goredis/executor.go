@@ -0,0 +1,75 @@
+// Package goredis runs the commands of redisearch clients with go-redis, so that they share the
+// connections of an application using it:
+//
+//	rdb := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+//	c := redisearch.NewClientFromExecutor(goredis.NewExecutor(rdb), "myIndex")
+package goredis
+
+import (
+	"context"
+
+	redigo "github.com/garyburd/redigo/redis"
+	"github.com/go-redis/redis/v7"
+
+	"github.com/RediSearch/redisearch-go/redisearch"
+)
+
+// Cmdable is the part of a go-redis client the executor uses. It is implemented by *redis.Client,
+// *redis.ClusterClient, *redis.Ring and redis.UniversalClient
+type Cmdable interface {
+	DoContext(ctx context.Context, args ...interface{}) *redis.Cmd
+	Pipeline() redis.Pipeliner
+}
+
+// NewExecutor creates an executor running the commands with a go-redis client
+func NewExecutor(c Cmdable) redisearch.Executor {
+	return executor{c}
+}
+
+type executor struct {
+	client Cmdable
+}
+
+func commandArgs(cmd string, args []interface{}) []interface{} {
+	return append([]interface{}{cmd}, args...)
+}
+
+// result returns the reply of a command the way redigo does: nil replies are nil and not
+// redis.Nil, and error replies are a redigo redis.Error, which the client classifies.
+// Other errors, such as network and context errors, are returned unchanged
+func result(cmd *redis.Cmd) (interface{}, error) {
+	reply, err := cmd.Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if rerr, ok := err.(redis.Error); ok {
+		return nil, redigo.Error(rerr.Error())
+	}
+	return reply, err
+}
+
+func (e executor) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	return result(e.client.DoContext(ctx, commandArgs(cmd, args)...))
+}
+
+func (e executor) Pipeline(ctx context.Context, cmds []redisearch.Command) ([]redisearch.Reply, error) {
+	pipe := e.client.Pipeline()
+	defer pipe.Close()
+
+	results := make([]*redis.Cmd, len(cmds))
+	for i, cmd := range cmds {
+		results[i] = redis.NewCmd(commandArgs(cmd.Name, cmd.Args)...)
+		pipe.Process(results[i])
+	}
+	// Exec fails with the first failed command, the replies tell apart server and network errors
+	pipe.ExecContext(ctx)
+
+	replies := make([]redisearch.Reply, len(cmds))
+	for i, res := range results {
+		replies[i].Value, replies[i].Err = result(res)
+		if _, ok := replies[i].Err.(redigo.Error); replies[i].Err != nil && !ok {
+			return replies[:i], replies[i].Err
+		}
+	}
+	return replies, nil
+}
@@ -0,0 +1,185 @@
+package goredis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	redigo "github.com/garyburd/redigo/redis"
+	"github.com/go-redis/redis/v7"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/RediSearch/redisearch-go/redisearch"
+)
+
+// fakeServer answers the commands it receives over RESP with the raw replies of a handler
+type fakeServer struct {
+	ln       net.Listener
+	handle   func(args []string) string
+	mu       sync.Mutex
+	commands []string
+}
+
+func newFakeServer(t *testing.T, handle func(args []string) string) *fakeServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeServer{ln: ln, handle: handle}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.commands = append(s.commands, strings.Join(args, " "))
+		s.mu.Unlock()
+		if _, err := conn.Write([]byte(s.handle(args))); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeServer) recorded() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// readCommand reads a command sent as an array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	readLine := func(prefix byte) (int, error) {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return 0, err
+		}
+		if len(line) < 3 || line[0] != prefix {
+			return 0, fmt.Errorf("unexpected line %q", line)
+		}
+		return strconv.Atoi(strings.TrimRight(line[1:], "\r\n"))
+	}
+	n, err := readLine('*')
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		size, err := readLine('$')
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func TestExecutor(t *testing.T) {
+	s := newFakeServer(t, func(args []string) string {
+		switch args[0] {
+		case "FT.SEARCH":
+			return "*3\r\n:1\r\n$4\r\ndoc1\r\n*2\r\n$5\r\ntitle\r\n$5\r\nhello\r\n"
+		case "FT.INFO":
+			return "-Unknown Index name\r\n"
+		case "GET":
+			return "$-1\r\n"
+		case "FT.ADD":
+			if args[2] == "doc2" {
+				return "-Document already exists\r\n"
+			}
+		}
+		return "+OK\r\n"
+	})
+	defer s.ln.Close()
+	rdb := redis.NewClient(&redis.Options{Addr: s.ln.Addr().String()})
+	defer rdb.Close()
+	exec := NewExecutor(rdb)
+	c := redisearch.NewClientFromExecutor(exec, "idx")
+
+	docs, total, err := c.Search(redisearch.NewQuery("hello"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "doc1", docs[0].Id)
+
+	// nil replies are nil, not redis.Nil
+	v, err := exec.Do(context.Background(), "GET", "k")
+	assert.Nil(t, v)
+	assert.Nil(t, err)
+
+	// error replies are classified like with redigo
+	_, err = c.Info()
+	assert.True(t, errors.Is(err, redisearch.ErrIndexNotFound))
+	_, err = exec.Do(context.Background(), "FT.INFO", "idx")
+	assert.Equal(t, redigo.Error("Unknown Index name"), err)
+
+	// a server error fails its document, not the whole pipeline
+	results, err := c.IndexWithResults(redisearch.DefaultIndexingOptions,
+		redisearch.NewDocument("doc1", 1), redisearch.NewDocument("doc2", 1), redisearch.NewDocument("doc3", 1))
+	assert.Nil(t, err)
+	assert.Nil(t, results[0].Err)
+	assert.True(t, results[1].Exists)
+	assert.True(t, errors.Is(results[1].Err, redisearch.ErrDocumentExists))
+	assert.Nil(t, results[2].Err)
+
+	replies, err := exec.Pipeline(context.Background(), []redisearch.Command{
+		{Name: "SET", Args: []interface{}{"k", "v"}},
+		{Name: "GET", Args: []interface{}{"k"}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []redisearch.Reply{{Value: "OK"}, {}}, replies)
+
+	var added []string
+	for _, cmd := range s.recorded() {
+		if strings.HasPrefix(cmd, "FT.ADD ") {
+			added = append(added, strings.Fields(cmd)[2])
+		}
+	}
+	assert.Equal(t, []string{"doc1", "doc2", "doc3"}, added)
+}
+
+func TestExecutorNetworkError(t *testing.T) {
+	s := newFakeServer(t, func(args []string) string { return "+OK\r\n" })
+	s.ln.Close()
+	rdb := redis.NewClient(&redis.Options{Addr: s.ln.Addr().String()})
+	defer rdb.Close()
+	exec := NewExecutor(rdb)
+
+	// errors that are not replies are returned unchanged, and fail the whole pipeline
+	_, err := exec.Do(context.Background(), "GET", "k")
+	var rerr redigo.Error
+	assert.NotNil(t, err)
+	assert.False(t, errors.As(err, &rerr))
+
+	replies, err := exec.Pipeline(context.Background(), []redisearch.Command{{Name: "GET", Args: []interface{}{"k"}}})
+	assert.NotNil(t, err)
+	assert.False(t, errors.As(err, &rerr))
+	assert.Empty(t, replies)
+
+	_, err = redisearch.NewClientFromExecutor(exec, "idx").IndexWithResults(redisearch.DefaultIndexingOptions, redisearch.NewDocument("doc1", 1))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,446 @@
+package redisearch
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// structTag is the struct tag used to map Go structs to schemas and documents
+const structTag = "redisearch"
+
+// Special roles of struct fields that are not indexed properties
+const (
+	roleID      = "id"
+	roleScore   = "score"
+	rolePayload = "payload"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// structField is a single mapped field of a Go struct, parsed from its redisearch tag
+type structField struct {
+	index []int
+	name  string
+	role  string
+	field Field
+}
+
+var structCache sync.Map // map[reflect.Type][]structField
+
+// inferFieldType guesses the index field type from the Go type of a struct field
+func inferFieldType(t reflect.Type) (FieldType, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return NumericField, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return TextField, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return NumericField, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			return TagField, nil
+		}
+	}
+	return 0, fmt.Errorf("Cannot infer field type for %v", t)
+}
+
+// parseStructTag parses the redisearch tag of a single struct field
+func parseStructTag(sf reflect.StructField, tag string) (structField, error) {
+	parts := strings.Split(tag, ",")
+	ret := structField{index: sf.Index, name: parts[0]}
+	if ret.name == "" {
+		ret.name = sf.Name
+	}
+
+	var typ string
+	var opts []string
+	if len(parts) > 1 {
+		typ = strings.ToLower(parts[1])
+		opts = parts[2:]
+	}
+	switch typ {
+	case roleID, roleScore, rolePayload:
+		ret.role = typ
+		return ret, nil
+	case "text":
+		ret.field = NewTextField(ret.name)
+	case "numeric":
+		ret.field = NewNumericField(ret.name)
+	case "tag":
+		ret.field = NewTagField(ret.name)
+	case "geo":
+		ret.field = NewGeoField(ret.name)
+	case "":
+		ft, err := inferFieldType(sf.Type)
+		if err != nil {
+			return ret, fmt.Errorf("Field %s: %s", sf.Name, err)
+		}
+		ret.field = Field{Name: ret.name, Type: ft}
+		if ft == TagField {
+			ret.field.Options = TagFieldOptions{Separator: ','}
+		}
+	default:
+		return ret, fmt.Errorf("Field %s: unknown field type %q", sf.Name, parts[1])
+	}
+
+	var text TextFieldOptions
+	var numeric NumericFieldOptions
+	var geo GeoFieldOptions
+	tagOpts := TagFieldOptions{Separator: ','}
+	hasOpts := false
+	for _, opt := range opts {
+		kv := strings.SplitN(opt, "=", 2)
+		key := strings.ToLower(strings.TrimSpace(kv[0]))
+		hasOpts = true
+		switch {
+		case key == "sortable":
+			text.Sortable, numeric.Sortable, tagOpts.Sortable = true, true, true
+		case key == "noindex":
+			text.NoIndex, numeric.NoIndex, tagOpts.NoIndex, geo.NoIndex = true, true, true, true
+		case key == "nostem" && ret.field.Type == TextField:
+			text.NoStem = true
+		case key == "phonetic" && ret.field.Type == TextField:
+			text.DMENPhonetic = true
+		case key == "weight" && len(kv) == 2 && ret.field.Type == TextField:
+			w, err := strconv.ParseFloat(kv[1], 32)
+			if err != nil {
+				return ret, fmt.Errorf("Field %s: invalid weight %q", sf.Name, kv[1])
+			}
+			text.Weight = float32(w)
+		case key == "separator" && len(kv) == 2 && ret.field.Type == TagField:
+			if len(kv[1]) != 1 {
+				return ret, fmt.Errorf("Field %s: separator must be a single character", sf.Name)
+			}
+			tagOpts.Separator = kv[1][0]
+		default:
+			return ret, fmt.Errorf("Field %s: invalid option %q", sf.Name, opt)
+		}
+	}
+
+	if hasOpts {
+		switch ret.field.Type {
+		case TextField:
+			ret.field.Options = text
+		case NumericField:
+			ret.field.Options = numeric
+		case TagField:
+			ret.field.Options = tagOpts
+		case GeoField:
+			ret.field.Options = geo
+		}
+	}
+	return ret, nil
+}
+
+// structFields returns the mapped fields of a struct type
+func structFields(t reflect.Type) ([]structField, error) {
+	if cached, ok := structCache.Load(t); ok {
+		return cached.([]structField), nil
+	}
+
+	var ret []structField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup(structTag)
+		if !ok || tag == "-" || sf.PkgPath != "" {
+			continue
+		}
+		f, err := parseStructTag(sf, tag)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, f)
+	}
+	structCache.Store(t, ret)
+	return ret, nil
+}
+
+// structValue dereferences v until it reaches a struct
+func structValue(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return rv, errors.New("Nil struct pointer")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return rv, fmt.Errorf("Expected a struct, got %v", rv.Type())
+	}
+	return rv, nil
+}
+
+// SchemaFromStruct derives an index schema from the redisearch tags of a struct, e.g.
+//
+//	type Article struct {
+//		ID    string    `redisearch:",id"`
+//		Title string    `redisearch:"title,text,weight=5,sortable"`
+//		Tags  []string  `redisearch:"tags,tag,separator=;"`
+//		Date  time.Time `redisearch:"date,numeric,sortable"`
+//	}
+//
+// The first element of the tag is the property name (the Go field name if empty, or "-" to skip the field),
+// followed by the type (text, numeric, tag or geo, inferred from the Go type if omitted) or a role
+// (id, score or payload), and the field options (weight=N, sortable, nostem, noindex, phonetic, separator=C).
+func SchemaFromStruct(v interface{}, opts Options) (*Schema, error) {
+	rv, err := structValue(v)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := structFields(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+	sc := NewSchema(opts)
+	sc.Options = opts
+	for _, f := range fields {
+		if f.role == "" {
+			sc.AddField(f.field)
+		}
+	}
+	return sc, nil
+}
+
+// tagSeparator returns the separator of a tag field, defaulting to comma
+func tagSeparator(f Field) string {
+	if opts, ok := f.Options.(TagFieldOptions); ok && opts.Separator != 0 {
+		return string(opts.Separator)
+	}
+	return ","
+}
+
+// DocumentFromStruct converts a struct with redisearch tags to a document.
+// Time fields are stored as unix timestamps, and string slices are joined with the tag separator
+func DocumentFromStruct(v interface{}) (Document, error) {
+	rv, err := structValue(v)
+	if err != nil {
+		return Document{}, err
+	}
+	fields, err := structFields(rv.Type())
+	if err != nil {
+		return Document{}, err
+	}
+
+	doc := NewDocument("", 1)
+	for _, f := range fields {
+		fv := rv.FieldByIndex(f.index)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+
+		switch f.role {
+		case roleID:
+			doc.Id = fmt.Sprint(fv.Interface())
+			continue
+		case roleScore:
+			if fv.Kind() != reflect.Float32 && fv.Kind() != reflect.Float64 {
+				return doc, fmt.Errorf("Field %s: score must be a float", f.name)
+			}
+			doc.Score = float32(fv.Float())
+			continue
+		case rolePayload:
+			switch p := fv.Interface().(type) {
+			case []byte:
+				doc.Payload = p
+			case string:
+				doc.Payload = []byte(p)
+			default:
+				return doc, fmt.Errorf("Field %s: payload must be a string or []byte", f.name)
+			}
+			continue
+		}
+
+		switch val := fv.Interface().(type) {
+		case time.Time:
+			doc.Set(f.name, val.Unix())
+		case []string:
+			doc.Set(f.name, strings.Join(val, tagSeparator(f.field)))
+		default:
+			doc.Set(f.name, val)
+		}
+	}
+	if doc.Id == "" {
+		return doc, errors.New("Missing document id")
+	}
+	return doc, nil
+}
+
+// setValue converts a property returned from the engine and stores it in the struct field
+func setValue(fv reflect.Value, f structField, value interface{}) error {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		fv = fv.Elem()
+	}
+
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		s = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case []string:
+		// TAG values converted by the schema are joined back as they are stored
+		s = strings.Join(v, tagSeparator(f.field))
+	default:
+		rv := reflect.ValueOf(value)
+		// reflect converts integers to strings as runes, so they are formatted instead
+		if fv.Kind() != reflect.String && rv.Type().ConvertibleTo(fv.Type()) {
+			fv.Set(rv.Convert(fv.Type()))
+			return nil
+		}
+		s = fmt.Sprint(value)
+	}
+
+	if fv.Type() == timeType {
+		secs, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(time.Unix(0, int64(secs*float64(time.Second)))))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			// numeric fields may be returned in float notation
+			fl, ferr := strconv.ParseFloat(s, 64)
+			if ferr != nil {
+				return err
+			}
+			n = int64(fl)
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			// numeric fields may be returned in float notation
+			fl, ferr := strconv.ParseFloat(s, 64)
+			if ferr != nil || fl < 0 {
+				return err
+			}
+			n = uint64(fl)
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("Unsupported type %v", fv.Type())
+		}
+		var tags []string
+		for _, t := range strings.Split(s, tagSeparator(f.field)) {
+			if t = strings.TrimSpace(t); t != "" {
+				tags = append(tags, t)
+			}
+		}
+		fv.Set(reflect.ValueOf(tags).Convert(fv.Type()))
+	default:
+		return fmt.Errorf("Unsupported type %v", fv.Type())
+	}
+	return nil
+}
+
+// DecodeDocument stores the id, score, payload and properties of a document in the struct pointed to by dst
+func DecodeDocument(doc Document, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("Destination must be a non nil pointer")
+	}
+	rv, err := structValue(dst)
+	if err != nil {
+		return err
+	}
+	fields, err := structFields(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		fv := rv.FieldByIndex(f.index)
+		var value interface{}
+		switch f.role {
+		case roleID:
+			value = doc.Id
+		case roleScore:
+			value = doc.Score
+		case rolePayload:
+			if doc.Payload == nil {
+				continue
+			}
+			value = doc.Payload
+		default:
+			var found bool
+			if value, found = doc.Properties[f.name]; !found || value == nil {
+				continue
+			}
+		}
+		if err := setValue(fv, f, value); err != nil {
+			return fmt.Errorf("Document %s, field %s: %s", doc.Id, f.name, err)
+		}
+	}
+	return nil
+}
+
+// DecodeDocuments decodes a list of documents, e.g. returned from Search, into the slice pointed to by dst.
+// The slice elements can be structs or pointers to structs
+func DecodeDocuments(docs []Document, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return errors.New("Destination must be a pointer to a slice")
+	}
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	out := reflect.MakeSlice(slice.Type(), 0, len(docs))
+	for _, doc := range docs {
+		elem := reflect.New(elemType)
+		if err := DecodeDocument(doc, elem.Interface()); err != nil {
+			return err
+		}
+		if isPtr {
+			out = reflect.Append(out, elem)
+		} else {
+			out = reflect.Append(out, elem.Elem())
+		}
+	}
+	slice.Set(out)
+	return nil
+}
@@ -0,0 +1,127 @@
+package redisearch
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testArticle struct {
+	ID      string    `redisearch:",id"`
+	Score   float64   `redisearch:",score"`
+	Title   string    `redisearch:"title,text,weight=5,sortable"`
+	Body    string    `redisearch:"body"`
+	Tags    []string  `redisearch:"tags,tag,separator=;"`
+	Views   int       `redisearch:"views,numeric,sortable"`
+	Date    time.Time `redisearch:"date"`
+	Ignored string    `redisearch:"-"`
+	Plain   string
+}
+
+func TestSchemaFromStruct(t *testing.T) {
+	sc, err := SchemaFromStruct(testArticle{}, DefaultOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NewSchema(DefaultOptions).
+		AddField(NewTextFieldOptions("title", TextFieldOptions{Weight: 5, Sortable: true})).
+		AddField(NewTextField("body")).
+		AddField(NewTagFieldOptions("tags", TagFieldOptions{Separator: ';'})).
+		AddField(NewNumericFieldOptions("views", NumericFieldOptions{Sortable: true})).
+		AddField(NewNumericField("date"))
+	if !reflect.DeepEqual(sc, want) {
+		t.Errorf("SchemaFromStruct() = %v, want %v", sc, want)
+	}
+
+	opts := Options{NoFieldFlags: true, Stopwords: []string{"a", "the"}}
+	if sc, err = SchemaFromStruct(testArticle{}, opts); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sc.Options, opts) {
+		t.Errorf("SchemaFromStruct() options = %v, want %v", sc.Options, opts)
+	}
+
+	type bad struct {
+		Title string `redisearch:"title,text,bogus"`
+	}
+	if _, err = SchemaFromStruct(&bad{}, DefaultOptions); err == nil {
+		t.Error("expected an error for an invalid option")
+	}
+}
+
+func TestStructDocumentRoundTrip(t *testing.T) {
+	a := testArticle{
+		ID:    "doc1",
+		Score: 0.5,
+		Title: "hello",
+		Body:  "world",
+		Tags:  []string{"foo", "bar baz"},
+		Views: 42,
+		Date:  time.Unix(1500000000, 0),
+	}
+	doc, err := DocumentFromStruct(&a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Id != "doc1" || doc.Score != 0.5 || doc.Properties["tags"] != "foo;bar baz" || doc.Properties["date"] != int64(1500000000) {
+		t.Errorf("DocumentFromStruct() = %v", doc)
+	}
+
+	// properties come back from the engine as strings
+	ret := NewDocument("doc1", 0.5)
+	for k, v := range doc.Properties {
+		ret.Set(k, fmt.Sprint(v))
+	}
+	var out []*testArticle
+	if err = DecodeDocuments([]Document{ret}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || !reflect.DeepEqual(*out[0], a) {
+		t.Errorf("DecodeDocuments() = %v, want %v", out, a)
+	}
+}
+
+func TestDecodeTypedValues(t *testing.T) {
+	type place struct {
+		Zip    string  `redisearch:"zip,numeric"`
+		Rating string  `redisearch:"rating,numeric"`
+		Count  uint    `redisearch:"count,numeric"`
+		Small  uint8   `redisearch:"small,numeric"`
+		Price  float64 `redisearch:"price,numeric"`
+		Tags   string  `redisearch:"tags,tag,separator=;"`
+	}
+	sc, err := SchemaFromStruct(place{}, DefaultOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// numeric properties converted by the schema are formatted, not converted to runes,
+	// and tags are joined with the field's separator
+	doc := NewDocument("doc1", 1).Set("zip", "94105").Set("rating", "4.5").
+		Set("count", "12").Set("small", "3").Set("price", "9.99").Set("tags", "x;y")
+	if err = sc.ConvertDocument(&doc); err != nil {
+		t.Fatal(err)
+	}
+	var p place
+	if err = DecodeDocument(doc, &p); err != nil {
+		t.Fatal(err)
+	}
+	want := place{Zip: "94105", Rating: "4.5", Count: 12, Small: 3, Price: 9.99, Tags: "x;y"}
+	if p != want {
+		t.Errorf("DecodeDocument() = %+v, want %+v", p, want)
+	}
+
+	// unsigned fields accept float notation, like signed ones
+	doc = NewDocument("doc2", 1).Set("count", "12.0").Set("small", "1e2")
+	p = place{}
+	if err = DecodeDocument(doc, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Count != 12 || p.Small != 100 {
+		t.Errorf("DecodeDocument() = %+v", p)
+	}
+	if err = DecodeDocument(NewDocument("doc3", 1).Set("count", "-1.5"), &p); err == nil {
+		t.Error("expected an error for a negative unsigned value")
+	}
+}
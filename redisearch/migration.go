@@ -0,0 +1,345 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// SchemaChangeKind is the kind of a difference between two schemas
+type SchemaChangeKind int
+
+const (
+	// FieldUnchanged is a field that is the same in both schemas
+	FieldUnchanged SchemaChangeKind = iota
+	// FieldRemoved is a field that is only in the current schema. Fields cannot be removed in place
+	FieldRemoved
+	// FieldChanged is a field whose type or options differ. Fields cannot be changed in place
+	FieldChanged
+	// OptionsChanged is a change of the index options or stopwords, which cannot be changed in place
+	OptionsChanged
+)
+
+// SchemaChange is a single difference between the current and the desired schema
+type SchemaChange struct {
+	Kind SchemaChangeKind
+	// Field is the name of the changed field, empty for index options
+	Field string
+	// Reason describes the change
+	Reason string
+}
+
+// MigrationPlan is the list of changes needed to turn an index into the desired schema
+type MigrationPlan struct {
+	Desired *Schema
+	// Create is set when the index does not exist, and is created from the desired schema
+	Create bool
+	// NoOps are the fields that are already as desired
+	NoOps []SchemaChange
+	// Additions are the fields that are added in place with FT.ALTER
+	Additions []Field
+	// Rebuilds are the changes that require rebuilding the index
+	Rebuilds []SchemaChange
+}
+
+// NeedsRebuild tells if executing the plan rebuilds the index
+func (p *MigrationPlan) NeedsRebuild() bool {
+	return !p.Create && len(p.Rebuilds) > 0
+}
+
+// Empty tells if the index is already as desired
+func (p *MigrationPlan) Empty() bool {
+	return !p.Create && len(p.Additions) == 0 && len(p.Rebuilds) == 0
+}
+
+// String describes the plan, one change per line
+func (p *MigrationPlan) String() string {
+	var b strings.Builder
+	for _, c := range p.changes() {
+		b.WriteString(c)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// changes describes every change of the plan
+func (p *MigrationPlan) changes() []string {
+	if p.Create {
+		return []string{"create index"}
+	}
+	var changes []string
+	for _, f := range p.Additions {
+		changes = append(changes, "add field "+f.Name)
+	}
+	for _, c := range p.Rebuilds {
+		changes = append(changes, "rebuild: "+c.Reason)
+	}
+	return changes
+}
+
+// fieldDef is the canonical definition of a field, with the defaults made explicit,
+// so that fields built differently but indexed the same way compare equal
+type fieldDef struct {
+	Type      FieldType
+	Weight    float32
+	Sortable  bool
+	NoStem    bool
+	NoIndex   bool
+	Phonetic  string
+	Separator byte
+}
+
+// canonicalField returns the definition of the field as it is created. Field.Sortable is ignored,
+// since only the Sortable flag of the options is sent to the server
+func canonicalField(f Field) fieldDef {
+	def := fieldDef{Type: f.Type}
+	switch opts := f.Options.(type) {
+	case TextFieldOptions:
+		def.Weight = opts.Weight
+		def.Sortable = opts.Sortable
+		def.NoStem = opts.NoStem
+		def.NoIndex = opts.NoIndex
+		def.Phonetic = opts.PhoneticMatcher
+		if def.Phonetic == "" && opts.DMENPhonetic {
+			def.Phonetic = "dm:en"
+		}
+	case NumericFieldOptions:
+		def.Sortable = opts.Sortable
+		def.NoIndex = opts.NoIndex
+	case TagFieldOptions:
+		def.Separator = opts.Separator
+		def.Sortable = opts.Sortable
+		def.NoIndex = opts.NoIndex
+	case GeoFieldOptions:
+		def.NoIndex = opts.NoIndex
+	}
+	if f.Type == TextField && def.Weight == 0 {
+		def.Weight = 1
+	}
+	if f.Type == TagField && def.Separator == 0 {
+		def.Separator = ','
+	}
+	return def
+}
+
+var fieldTypeNames = map[FieldType]string{
+	TextField:    "TEXT",
+	NumericField: "NUMERIC",
+	GeoField:     "GEO",
+	TagField:     "TAG",
+}
+
+// describeFieldChange tells how the definition of a field changed
+func describeFieldChange(name string, from, to fieldDef) string {
+	if from.Type != to.Type {
+		return fmt.Sprintf("field %s changes type from %s to %s", name, fieldTypeNames[from.Type], fieldTypeNames[to.Type])
+	}
+	var changes []string
+	if from.Weight != to.Weight {
+		changes = append(changes, fmt.Sprintf("weight %v to %v", from.Weight, to.Weight))
+	}
+	if from.Separator != to.Separator {
+		changes = append(changes, fmt.Sprintf("separator %q to %q", from.Separator, to.Separator))
+	}
+	if from.Phonetic != to.Phonetic {
+		changes = append(changes, fmt.Sprintf("phonetic matcher %q to %q", from.Phonetic, to.Phonetic))
+	}
+	flags := []struct {
+		name     string
+		from, to bool
+	}{
+		{"SORTABLE", from.Sortable, to.Sortable},
+		{"NOSTEM", from.NoStem, to.NoStem},
+		{"NOINDEX", from.NoIndex, to.NoIndex},
+	}
+	for _, fl := range flags {
+		if fl.from != fl.to {
+			changes = append(changes, fmt.Sprintf("%s %v to %v", fl.name, fl.from, fl.to))
+		}
+	}
+	return fmt.Sprintf("field %s changes %s", name, strings.Join(changes, ", "))
+}
+
+// stopwordsEqual compares stopword lists regardless of order. A nil list, meaning the default
+// stopwords, differs from an empty one, meaning no stopwords
+func stopwordsEqual(a, b []string) bool {
+	if (a == nil) != (b == nil) || len(a) != len(b) {
+		return false
+	}
+	sa := append([]string(nil), a...)
+	sb := append([]string(nil), b...)
+	sort.Strings(sa)
+	sort.Strings(sb)
+	for i := range sa {
+		if sa[i] != sb[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// DiffSchemas compares the current schema of an index, e.g. returned by Client.Info, with the
+// desired one, and returns the changes needed. A nil current schema means the index does not exist
+func DiffSchemas(current, desired *Schema) *MigrationPlan {
+	plan := &MigrationPlan{Desired: desired}
+	if current == nil {
+		plan.Create = true
+		return plan
+	}
+
+	co, do := current.Options, desired.Options
+	if co.NoFieldFlags != do.NoFieldFlags || co.NoFrequencies != do.NoFrequencies || co.NoOffsetVectors != do.NoOffsetVectors ||
+		co.MaxTextFields != do.MaxTextFields {
+		plan.Rebuilds = append(plan.Rebuilds, SchemaChange{Kind: OptionsChanged, Reason: "index options change"})
+	}
+	if !stopwordsEqual(co.Stopwords, do.Stopwords) {
+		plan.Rebuilds = append(plan.Rebuilds, SchemaChange{Kind: OptionsChanged, Reason: "stopwords change"})
+	}
+
+	currentFields := make(map[string]Field, len(current.Fields))
+	for _, f := range current.Fields {
+		currentFields[f.Name] = f
+	}
+	desiredFields := make(map[string]bool, len(desired.Fields))
+	for _, f := range desired.Fields {
+		desiredFields[f.Name] = true
+		cf, found := currentFields[f.Name]
+		if !found {
+			plan.Additions = append(plan.Additions, f)
+			continue
+		}
+		from, to := canonicalField(cf), canonicalField(f)
+		if from == to {
+			plan.NoOps = append(plan.NoOps, SchemaChange{Kind: FieldUnchanged, Field: f.Name, Reason: "field " + f.Name + " is unchanged"})
+		} else {
+			plan.Rebuilds = append(plan.Rebuilds, SchemaChange{Kind: FieldChanged, Field: f.Name, Reason: describeFieldChange(f.Name, from, to)})
+		}
+	}
+	for _, f := range current.Fields {
+		if !desiredFields[f.Name] {
+			plan.Rebuilds = append(plan.Rebuilds, SchemaChange{Kind: FieldRemoved, Field: f.Name, Reason: "field " + f.Name + " is removed"})
+		}
+	}
+	return plan
+}
+
+// PlanMigration compares the index's current schema with the desired one, and returns the changes needed
+func (i *Client) PlanMigration(desired *Schema) (*MigrationPlan, error) {
+	return i.PlanMigrationContext(context.Background(), desired)
+}
+
+// PlanMigrationContext is like PlanMigration, but honors the context's cancellation and deadline
+func (i *Client) PlanMigrationContext(ctx context.Context, desired *Schema) (*MigrationPlan, error) {
+	info, err := i.InfoContext(ctx)
+	if errors.Is(err, ErrIndexNotFound) {
+		return DiffSchemas(nil, desired), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return DiffSchemas(&info.Schema, desired), nil
+}
+
+// Execute applies the plan to the client's index: the index is created if missing, new fields are
+// added in place, and if any other change is needed the index is rebuilt, see RebuildIndex
+func (p *MigrationPlan) Execute(c *Client) error {
+	return p.ExecuteContext(context.Background(), c)
+}
+
+// ExecuteContext is like Execute, but honors the context's cancellation and deadline
+func (p *MigrationPlan) ExecuteContext(ctx context.Context, c *Client) error {
+	switch {
+	case p.Create:
+		return c.CreateIndexContext(ctx, p.Desired)
+	case p.NeedsRebuild():
+		return c.RebuildIndexContext(ctx, p.Desired)
+	}
+	// the plan was made against the current schema, so that without rebuilds the desired schema
+	// is the current one with the additions, and is validated as a whole
+	if len(p.Additions) > 0 {
+		if err := p.Desired.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, f := range p.Additions {
+		if err := c.alterAddField(ctx, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuildPageSize is the number of document ids read per request when rebuilding an index
+const rebuildPageSize = 1000
+
+// RebuildIndex recreates the index with the given schema, and re-indexes its documents from their hashes.
+// The index is unavailable, or returns partial results, while it is rebuilt. Documents indexed with
+// NoSave cannot be re-indexed, and the scores of the documents are reset to 1.
+// If some documents could not be re-indexed, a MultiError of *DocumentError is returned
+func (i *Client) RebuildIndex(s *Schema) error {
+	return i.RebuildIndexContext(context.Background(), s)
+}
+
+// RebuildIndexContext is like RebuildIndex, but honors the context's cancellation and deadline
+func (i *Client) RebuildIndexContext(ctx context.Context, s *Schema) error {
+	// an invalid schema must fail before the index is dropped
+	createArgs, err := i.createArgs(s)
+	if err != nil {
+		return err
+	}
+
+	var ids []string
+	it := i.SearchIteratorContext(ctx, NewQuery("*").SetFlags(QueryNoContent)).SetPageSize(rebuildPageSize)
+	for it.Next() {
+		ids = append(ids, it.Doc().Id)
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	conn, err := getConn(ctx, i.indexingPool())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err = conn.Do("FT.DROP", i.name, "KEEPDOCS"); err != nil {
+		return err
+	}
+	if _, err = conn.Do("FT.CREATE", createArgs...); err != nil {
+		return err
+	}
+
+	var merr MultiError
+	for start := 0; start < len(ids); start += contextPipelineSize {
+		end := start + contextPipelineSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		for _, id := range ids[start:end] {
+			if err := conn.Send("FT.ADDHASH", i.name, id, 1.0, "REPLACE"); err != nil {
+				return err
+			}
+		}
+		if err := conn.Flush(); err != nil {
+			return err
+		}
+		for _, id := range ids[start:end] {
+			if _, err := conn.Receive(); err != nil {
+				var rerr redis.Error
+				if !errors.As(err, &rerr) {
+					return err
+				}
+				merr = append(merr, &DocumentError{Id: id, Err: err})
+			}
+		}
+	}
+	if merr != nil {
+		return merr
+	}
+	return nil
+}
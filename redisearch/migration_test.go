@@ -0,0 +1,151 @@
+package redisearch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	current := NewSchema(DefaultOptions).
+		AddField(NewTextField("title")).
+		AddField(NewTagField("tags")).
+		AddField(NewSortableNumericField("price"))
+
+	tests := []struct {
+		name     string
+		desired  *Schema
+		noops    int
+		adds     []string
+		rebuilds []SchemaChangeKind
+	}{
+		{"same", NewSchema(DefaultOptions).
+			AddField(NewTextFieldOptions("title", TextFieldOptions{Weight: 1})).
+			AddField(NewTagFieldOptions("tags", TagFieldOptions{Separator: ','})).
+			AddField(NewNumericFieldOptions("price", NumericFieldOptions{Sortable: true})),
+			3, nil, nil},
+		{"added", NewSchema(DefaultOptions).
+			AddField(NewTextField("title")).
+			AddField(NewTagField("tags")).
+			AddField(NewSortableNumericField("price")).
+			AddField(NewTextField("body")),
+			3, []string{"body"}, nil},
+		{"changed", NewSchema(DefaultOptions).
+			AddField(NewNumericField("title")).
+			AddField(NewTagFieldOptions("tags", TagFieldOptions{Separator: ';'})).
+			AddField(NewNumericField("price")),
+			0, nil, []SchemaChangeKind{FieldChanged, FieldChanged, FieldChanged}},
+		{"removed", NewSchema(DefaultOptions).
+			AddField(NewTextField("title")).
+			AddField(NewTagField("tags")),
+			2, nil, []SchemaChangeKind{FieldRemoved}},
+		{"max text fields", NewSchema(Options{MaxTextFields: true}).
+			AddField(NewTextField("title")).
+			AddField(NewTagField("tags")).
+			AddField(NewSortableNumericField("price")),
+			3, nil, []SchemaChangeKind{OptionsChanged}},
+		{"stopwords", NewSchema(Options{Stopwords: []string{}}).
+			AddField(NewTextField("title")).
+			AddField(NewTagField("tags")).
+			AddField(NewSortableNumericField("price")),
+			3, nil, []SchemaChangeKind{OptionsChanged}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := DiffSchemas(current, tt.desired)
+			assert.False(t, plan.Create)
+			assert.Equal(t, tt.noops, len(plan.NoOps))
+			var adds []string
+			for _, f := range plan.Additions {
+				adds = append(adds, f.Name)
+			}
+			assert.Equal(t, tt.adds, adds)
+			var kinds []SchemaChangeKind
+			for _, c := range plan.Rebuilds {
+				kinds = append(kinds, c.Kind)
+			}
+			assert.Equal(t, tt.rebuilds, kinds)
+			assert.Equal(t, tt.rebuilds != nil, plan.NeedsRebuild())
+			assert.Equal(t, tt.adds == nil && tt.rebuilds == nil, plan.Empty())
+		})
+	}
+
+	plan := DiffSchemas(nil, current)
+	assert.True(t, plan.Create)
+	assert.False(t, plan.Empty())
+}
+
+func TestDiffSchemasLoaded(t *testing.T) {
+	declared := NewSchema(DefaultOptions).
+		AddField(NewTextFieldOptions("title", TextFieldOptions{Weight: 2, Sortable: true})).
+		AddField(NewTextField("body")).
+		AddField(Field{Name: "n", Type: NumericField, Sortable: true}).
+		AddField(Field{Name: "tags", Type: TagField, Sortable: true, Options: TagFieldOptions{Separator: ';'}}).
+		AddField(NewGeoField("location"))
+
+	// FT.INFO reports the fields as they were created
+	var fields []interface{}
+	for _, f := range declared.Fields {
+		args, err := serializeField(f)
+		assert.Nil(t, err)
+		spec := []interface{}{[]byte(f.Name), []byte("type")}
+		for _, arg := range args[1:] {
+			spec = append(spec, []byte(fmt.Sprint(arg)))
+		}
+		fields = append(fields, spec)
+	}
+	info := IndexInfo{}
+	info.loadSchema(fields, nil, nil)
+
+	plan := DiffSchemas(&info.Schema, declared)
+	assert.True(t, plan.Empty(), plan.String())
+	assert.Equal(t, len(declared.Fields), len(plan.NoOps))
+}
+
+func TestMigrationPlanExecute(t *testing.T) {
+	current := NewSchema(DefaultOptions).AddField(NewTextField("title"))
+
+	e := &recordingExecutor{}
+	c := NewClientFromExecutor(e.exec(), "idx")
+	plan := DiffSchemas(current, NewSchema(DefaultOptions).
+		AddField(NewTextField("title")).
+		AddField(NewNumericField("price")))
+	assert.Nil(t, plan.Execute(c))
+	assert.Equal(t, "FT.ALTER", strings.Join(e.commands, " "))
+
+	e = &recordingExecutor{replies: map[string]interface{}{
+		"FT.SEARCH": []interface{}{int64(2), "doc1", "doc2"},
+	}}
+	c = NewClientFromExecutor(e.exec(), "idx")
+	plan = DiffSchemas(current, NewSchema(DefaultOptions).AddField(NewTagField("title")))
+	assert.True(t, plan.NeedsRebuild())
+	assert.Nil(t, plan.Execute(c))
+	assert.Equal(t, "FT.SEARCH FT.DROP FT.CREATE FT.ADDHASH FT.ADDHASH", strings.Join(e.commands, " "))
+
+	e = &recordingExecutor{replies: map[string]interface{}{
+		"FT.SEARCH": []interface{}{int64(1), "doc1"},
+	}}
+	c = NewClientFromExecutor(e.exec(), "idx")
+	invalid := NewSchema(DefaultOptions).AddField(Field{Name: "title", Type: TextField, Options: NumericFieldOptions{}})
+	assert.NotNil(t, c.RebuildIndex(invalid))
+	invalid = NewSchema(DefaultOptions).AddField(Field{Name: "title", Type: TagField, Options: NumericFieldOptions{}})
+	plan = DiffSchemas(current, invalid)
+	assert.True(t, plan.NeedsRebuild())
+	assert.NotNil(t, plan.Execute(c))
+	assert.Empty(t, e.commands)
+
+	// additions are checked against the limits of the whole schema
+	full := NewSchema(DefaultOptions)
+	for n := 0; n < maxTextFields; n++ {
+		full.AddField(NewTextField(fmt.Sprintf("f%d", n)))
+	}
+	desired := *full
+	desired.Fields = append(append([]Field{}, full.Fields...), NewTextField("body"))
+	plan = DiffSchemas(full, &desired)
+	assert.Equal(t, 1, len(plan.Additions))
+	assert.False(t, plan.NeedsRebuild())
+	assert.NotNil(t, plan.Execute(c))
+	assert.Empty(t, e.commands)
+}
@@ -1,6 +1,7 @@
 package redisearch
 
 import (
+	"errors"
 	"fmt"
 )
 
@@ -23,3 +24,62 @@ func (e MultiError) Error() string {
 	}
 	return ret
 }
+
+// Is reports whether any sub error matches target. Together with As, it lets errors.Is and
+// errors.As match the sub errors before Go 1.20, which ignores Unwrap() []error
+func (e MultiError) Is(target error) bool {
+	for _, err := range e {
+		if err != nil && errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first sub error that matches target, and sets target to it
+func (e MultiError) As(target interface{}) bool {
+	for _, err := range e {
+		if err != nil && errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns the non nil sub errors, so that errors.Is and errors.As match any of them
+func (e MultiError) Unwrap() []error {
+	ret := make([]error, 0, len(e))
+	for _, err := range e {
+		if err != nil {
+			ret = append(ret, err)
+		}
+	}
+	return ret
+}
+
+// FailedIDs returns the ids of the documents whose sub errors are a *DocumentError
+func (e MultiError) FailedIDs() []string {
+	var ret []string
+	for _, err := range e {
+		if derr, ok := err.(*DocumentError); ok {
+			ret = append(ret, derr.Id)
+		}
+	}
+	return ret
+}
+
+// DocumentError is the error of a single document in a multi document operation
+type DocumentError struct {
+	Id  string
+	Err error
+}
+
+// Error returns the document id and the underlying error
+func (e *DocumentError) Error() string {
+	return fmt.Sprintf("Document %s: %s", e.Id, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *DocumentError) Unwrap() error {
+	return e.Err
+}
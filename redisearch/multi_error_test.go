@@ -0,0 +1,62 @@
+package redisearch
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+func TestMultiError(t *testing.T) {
+	exists := redis.Error("Document already exists")
+	merr := NewMultiError(3)
+	merr[0] = &DocumentError{Id: "doc1", Err: exists}
+	merr[2] = &DocumentError{Id: "doc3", Err: errors.New("boom")}
+
+	var err error = merr
+	if !errors.Is(err, exists) {
+		t.Error("errors.Is() did not match a sub error")
+	}
+	var derr *DocumentError
+	if !errors.As(err, &derr) || derr.Id != "doc1" {
+		t.Errorf("errors.As() = %v", derr)
+	}
+	// the methods used by errors.Is and errors.As before Go 1.20
+	if !merr.Is(exists) || merr.Is(redis.Error("other")) {
+		t.Error("Is() did not match only the sub errors")
+	}
+	derr = nil
+	if !merr.As(&derr) || derr.Id != "doc1" {
+		t.Errorf("As() = %v", derr)
+	}
+	if got := merr.FailedIDs(); !reflect.DeepEqual(got, []string{"doc1", "doc3"}) {
+		t.Errorf("FailedIDs() = %v", got)
+	}
+}
+
+func TestIndexWithResults(t *testing.T) {
+	pool := &fakePool{reply: func(id string) error {
+		if id == "doc2" {
+			return redis.Error("Document already exists")
+		}
+		return nil
+	}}
+	c := &Client{pool: pool, name: "idx"}
+
+	docs := []Document{NewDocument("doc1", 1), NewDocument("doc2", 1), NewDocument("doc3", 1)}
+	results, err := c.IndexWithResults(DefaultIndexingOptions, docs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, res := range results {
+		if res.Id != docs[i].Id || (res.Err != nil) != (i == 1) || res.Exists != (i == 1) {
+			t.Errorf("result %d = %+v", i, res)
+		}
+	}
+
+	merr, ok := c.IndexOptions(DefaultIndexingOptions, docs...).(MultiError)
+	if !ok || merr[0] != nil || merr[1] == nil || merr[2] != nil {
+		t.Errorf("IndexOptions() = %v", merr)
+	}
+}
@@ -0,0 +1,315 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// ErrNoHosts is returned when a MultiHostPool has no host to connect to
+var ErrNoHosts = errors.New("No hosts available")
+
+// MultiHostPoolOptions configure host selection and failure handling of a MultiHostPool
+type MultiHostPoolOptions struct {
+	// Client are the options used to dial and pool the connections to each host
+	Client ClientOptions
+	// Balancer selects a host among the healthy ones. Defaults to a random balancer
+	Balancer Balancer
+	// HealthCheckInterval is the interval between PINGs to every host. Zero disables health checks,
+	// in which case hosts are only ejected when connecting to them fails
+	HealthCheckInterval time.Duration
+	// MaxFailures is the number of consecutive failures after which a host is ejected
+	MaxFailures int
+	// EjectTimeout is the time after which an ejected host is tried again
+	EjectTimeout time.Duration
+}
+
+// DefaultMultiHostPoolOptions are the default options for multi host pools
+var DefaultMultiHostPoolOptions = MultiHostPoolOptions{
+	Client:              DefaultClientOptions,
+	HealthCheckInterval: 0,
+	MaxFailures:         1,
+	EjectTimeout:        5 * time.Second,
+}
+
+type hostPool struct {
+	addr         string
+	weight       int
+	pool         *redis.Pool
+	failures     int
+	ejectedUntil time.Time
+}
+
+func (h *hostPool) healthy(now time.Time) bool {
+	return !now.Before(h.ejectedUntil)
+}
+
+func (h *hostPool) info(now time.Time) HostInfo {
+	return HostInfo{
+		Addr:     h.addr,
+		Weight:   h.weight,
+		Active:   h.pool.ActiveCount(),
+		Healthy:  h.healthy(now),
+		Failures: h.failures,
+	}
+}
+
+// MultiHostPool is a pool of connections to several equivalent hosts. Hosts that fail to connect
+// or to answer health checks are ejected for a while, and hosts can be added and removed at runtime
+type MultiHostPool struct {
+	mu    sync.RWMutex
+	hosts []*hostPool
+	opts  MultiHostPoolOptions
+
+	stop      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+func NewMultiHostPool(hosts []string) ConnPool {
+	return NewMultiHostPoolOptions(hosts, DefaultClientOptions)
+}
+
+// NewMultiHostPoolOptions creates a pool of connections to random hosts, dialed with the given options
+func NewMultiHostPoolOptions(hosts []string, opts ClientOptions) ConnPool {
+	mopts := DefaultMultiHostPoolOptions
+	mopts.Client = opts
+	return NewBalancedPool(hosts, mopts)
+}
+
+// NewBalancedPool creates a pool of connections to the given hosts, with health checks and
+// host selection configured by opts. Close must be called to stop the health checks
+func NewBalancedPool(hosts []string, opts MultiHostPoolOptions) *MultiHostPool {
+	if opts.Balancer == nil {
+		opts.Balancer = NewRandomBalancer()
+	}
+	if opts.MaxFailures <= 0 {
+		opts.MaxFailures = 1
+	}
+	p := &MultiHostPool{
+		opts: opts,
+		stop: make(chan struct{}),
+	}
+	for _, h := range hosts {
+		p.AddHost(h, 1)
+	}
+	if opts.HealthCheckInterval > 0 {
+		p.wg.Add(1)
+		go p.healthCheckLoop()
+	}
+	return p
+}
+
+// AddHost adds a host to the pool with the given weight, or updates the weight of an existing host
+func (p *MultiHostPool) AddHost(addr string, weight int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, h := range p.hosts {
+		if h.addr == addr {
+			h.weight = weight
+			return
+		}
+	}
+	p.hosts = append(p.hosts, &hostPool{
+		addr:   addr,
+		weight: weight,
+		pool:   p.opts.Client.newPool(addr),
+	})
+}
+
+// RemoveHost removes a host from the pool. Its idle connections are closed, and the borrowed
+// ones are closed when they are released
+func (p *MultiHostPool) RemoveHost(addr string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, h := range p.hosts {
+		if h.addr == addr {
+			h.pool.Close()
+			p.hosts = append(p.hosts[:i], p.hosts[i+1:]...)
+			return
+		}
+	}
+}
+
+// Hosts returns the current state of the pool's hosts
+func (p *MultiHostPool) Hosts() []HostInfo {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	now := time.Now()
+	ret := make([]HostInfo, len(p.hosts))
+	for i, h := range p.hosts {
+		ret[i] = h.info(now)
+	}
+	return ret
+}
+
+func (p *MultiHostPool) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.stop)
+	})
+	p.wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, h := range p.hosts {
+		h.pool.Close()
+	}
+	return nil
+}
+
+// Get gets a connection from a healthy host. If connecting fails, the host is marked as failed
+// and the other hosts are tried. If all the hosts fail, the returned connection holds the last error
+func (p *MultiHostPool) Get() redis.Conn {
+	conn, err := p.get(context.Background(), false)
+	if err != nil {
+		return errorConn{err}
+	}
+	return conn
+}
+
+// GetContext is like Get, but gives up waiting for a connection when the context is done
+func (p *MultiHostPool) GetContext(ctx context.Context) (redis.Conn, error) {
+	return p.get(ctx, true)
+}
+
+func (p *MultiHostPool) get(ctx context.Context, useContext bool) (redis.Conn, error) {
+	tried := make(map[*hostPool]bool)
+	lastErr := ErrNoHosts
+	for {
+		h := p.pick(tried)
+		if h == nil {
+			return nil, lastErr
+		}
+		tried[h] = true
+
+		var conn redis.Conn
+		var err error
+		if useContext {
+			conn, err = h.pool.GetContext(ctx)
+		} else {
+			conn = h.pool.Get()
+		}
+		if err == nil {
+			err = conn.Err()
+		}
+		if err == nil {
+			p.markSuccess(h)
+			return conn, nil
+		}
+		if conn != nil {
+			conn.Close()
+		}
+		if err == redis.ErrPoolExhausted || ctx.Err() != nil {
+			// the host is fine, we are out of connections or time
+			return nil, err
+		}
+		p.markFailure(h)
+		lastErr = err
+	}
+}
+
+// pick selects a host that was not tried yet using the balancer. Healthy hosts are preferred,
+// but if all of them were tried, ejected hosts are tried as a last resort
+func (p *MultiHostPool) pick(tried map[*hostPool]bool) *hostPool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	now := time.Now()
+	var candidates []*hostPool
+	for _, h := range p.hosts {
+		if !tried[h] && h.healthy(now) {
+			candidates = append(candidates, h)
+		}
+	}
+	if len(candidates) == 0 {
+		for _, h := range p.hosts {
+			if !tried[h] {
+				candidates = append(candidates, h)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	infos := make([]HostInfo, len(candidates))
+	for i, h := range candidates {
+		infos[i] = h.info(now)
+	}
+	idx := p.opts.Balancer.Pick(infos)
+	if idx < 0 || idx >= len(candidates) {
+		idx = 0
+	}
+	return candidates[idx]
+}
+
+func (p *MultiHostPool) markSuccess(h *hostPool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h.failures = 0
+	h.ejectedUntil = time.Time{}
+}
+
+func (p *MultiHostPool) markFailure(h *hostPool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h.failures++
+	if h.failures >= p.opts.MaxFailures {
+		h.ejectedUntil = time.Now().Add(p.opts.EjectTimeout)
+	}
+}
+
+func (p *MultiHostPool) healthCheckLoop() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.opts.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.checkHosts()
+		}
+	}
+}
+
+// checkHosts PINGs every host, ejecting the failing ones and re-admitting the ones that recovered
+func (p *MultiHostPool) checkHosts() {
+	p.mu.RLock()
+	hosts := make([]*hostPool, len(p.hosts))
+	copy(hosts, p.hosts)
+	p.mu.RUnlock()
+
+	for _, h := range hosts {
+		if err := p.probe(h); err != nil {
+			p.markFailure(h)
+		} else {
+			p.markSuccess(h)
+		}
+	}
+}
+
+// probe PINGs the host on a dedicated connection, so that a host whose pool is exhausted is not
+// waited for, and waits for the reply at most one health check interval
+func (p *MultiHostPool) probe(h *hostPool) error {
+	conn, err := h.pool.Dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = redis.DoWithTimeout(conn, p.opts.HealthCheckInterval, "PING")
+	return err
+}
+
+// errorConn is a connection that fails every operation with the same error
+type errorConn struct{ err error }
+
+func (c errorConn) Do(string, ...interface{}) (interface{}, error) { return nil, c.err }
+func (c errorConn) Send(string, ...interface{}) error              { return c.err }
+func (c errorConn) Err() error                                     { return c.err }
+func (c errorConn) Close() error                                   { return nil }
+func (c errorConn) Flush() error                                   { return c.err }
+func (c errorConn) Receive() (interface{}, error)                  { return nil, c.err }
@@ -0,0 +1,132 @@
+package redisearch
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalancers(t *testing.T) {
+	hosts := []HostInfo{
+		{Addr: "a", Weight: 0, Active: 3},
+		{Addr: "b", Weight: 5, Active: 1},
+		{Addr: "c", Weight: 0, Active: 2},
+	}
+	tests := []struct {
+		name     string
+		balancer Balancer
+		want     []int
+	}{
+		{"round robin", NewRoundRobinBalancer(), []int{0, 1, 2, 0}},
+		{"least active", NewLeastActiveBalancer(), []int{1, 1}},
+		{"func", BalancerFunc(func([]HostInfo) int { return 2 }), []int{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, want := range tt.want {
+				assert.Equal(t, want, tt.balancer.Pick(hosts))
+			}
+		})
+	}
+
+	for _, b := range []Balancer{NewRandomBalancer(), NewWeightedBalancer()} {
+		for n := 0; n < 100; n++ {
+			idx := b.Pick(hosts)
+			assert.True(t, idx >= 0 && idx < len(hosts))
+		}
+	}
+}
+
+func TestMultiHostPoolFailover(t *testing.T) {
+	live, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("cannot listen: ", err)
+	}
+	defer live.Close()
+	commands := make(chan string, 10)
+	go serveCommands(t, live, commands)
+
+	// a port nothing listens on anymore
+	dead, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("cannot listen: ", err)
+	}
+	deadAddr := dead.Addr().String()
+	dead.Close()
+
+	opts := DefaultMultiHostPoolOptions
+	opts.Client.ConnectTimeout = time.Second
+	opts.Balancer = NewRoundRobinBalancer()
+	opts.EjectTimeout = time.Minute
+	p := NewBalancedPool([]string{deadAddr, live.Addr().String()}, opts)
+	defer p.Close()
+
+	conn := p.Get()
+	assert.Nil(t, conn.Err())
+	_, err = conn.Do("PING")
+	assert.Nil(t, err)
+	assert.Equal(t, "PING", <-commands)
+
+	hosts := p.Hosts()
+	assert.Equal(t, 2, len(hosts))
+	assert.False(t, hosts[0].Healthy)
+	assert.Equal(t, 1, hosts[0].Failures)
+	assert.True(t, hosts[1].Healthy)
+	assert.Equal(t, 1, hosts[1].Active)
+	conn.Close()
+
+	p.RemoveHost(live.Addr().String())
+	p.AddHost(deadAddr, 3)
+	hosts = p.Hosts()
+	assert.Equal(t, 1, len(hosts))
+	assert.Equal(t, 3, hosts[0].Weight)
+
+	// ejected hosts are still tried when no healthy host is left
+	conn = p.Get()
+	assert.NotNil(t, conn.Err())
+	assert.Equal(t, 2, p.Hosts()[0].Failures)
+
+	p.RemoveHost(deadAddr)
+	assert.Equal(t, ErrNoHosts, p.Get().Err())
+}
+
+func TestMultiHostPoolHealthCheckExhausted(t *testing.T) {
+	live, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("cannot listen: ", err)
+	}
+	defer live.Close()
+	commands := make(chan string, 10)
+	// one connection is borrowed, the other is the health check's
+	go serveCommands(t, live, commands)
+	go serveCommands(t, live, commands)
+
+	opts := DefaultMultiHostPoolOptions
+	opts.Client.PoolSize = 1
+	opts.HealthCheckInterval = time.Hour
+	p := NewBalancedPool([]string{live.Addr().String()}, opts)
+	defer p.Close()
+
+	conn := p.Get()
+	assert.Nil(t, conn.Err())
+	defer conn.Close()
+	p.markFailure(p.hosts[0])
+	assert.False(t, p.Hosts()[0].Healthy)
+
+	// the pool is exhausted, and the health check must not wait for it
+	done := make(chan struct{})
+	go func() {
+		p.checkHosts()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the health check waits for the exhausted pool")
+	}
+	assert.Equal(t, "PING", <-commands)
+	assert.True(t, p.Hosts()[0].Healthy)
+	assert.Equal(t, 1, p.Hosts()[0].Active)
+}
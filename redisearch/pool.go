@@ -1,9 +1,9 @@
 package redisearch
 
 import (
+	"crypto/tls"
 	"io"
-	"math/rand"
-	"sync"
+	"strings"
 	"time"
 
 	"github.com/garyburd/redigo/redis"
@@ -14,66 +14,112 @@ type ConnPool interface {
 	io.Closer
 }
 
-type singleHostPool struct {
-	*redis.Pool
+// ClientOptions configure how connections to the redis hosts are dialed and pooled
+type ClientOptions struct {
+	// ConnectTimeout, ReadTimeout and WriteTimeout bound dialing, and every read and write on a connection.
+	// Zero means no timeout
+	ConnectTimeout time.Duration
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+
+	// Username is the ACL user to authenticate as. If empty, Password is sent with the legacy AUTH command
+	Username string
+	Password string
+
+	// Database is the database selected on every new connection
+	Database int
+
+	// TLSConfig enables TLS connections when set
+	TLSConfig *tls.Config
+
+	// PoolSize is the maximal number of connections to each host. Get waits for a connection to be
+	// released when the pool is exhausted. Zero keeps up to 500 idle connections, without limiting active ones
+	PoolSize int
+
+	// IdleTimeout closes connections that stayed idle in the pool for longer. Zero keeps them open
+	IdleTimeout time.Duration
 }
 
-func NewSingleHostPool(host string) ConnPool {
-	ret := redis.NewPool(func() (redis.Conn, error) {
-		// TODO: Add timeouts. and 2 separate pools for indexing and querying, with different timeouts
-		return redis.Dial("tcp", host)
-	}, maxConns)
-	ret.TestOnBorrow = func(c redis.Conn, t time.Time) (err error) {
-		if time.Since(t) > time.Second {
-			_, err = c.Do("PING")
+// DefaultClientOptions are the default options for connecting to redis
+var DefaultClientOptions = ClientOptions{}
+
+// dialFunc returns a function dialing the given host with the options
+func (o ClientOptions) dialFunc(host string) func() (redis.Conn, error) {
+	dialOpts := []redis.DialOption{
+		redis.DialConnectTimeout(o.ConnectTimeout),
+		redis.DialReadTimeout(o.ReadTimeout),
+		redis.DialWriteTimeout(o.WriteTimeout),
+	}
+	if o.TLSConfig != nil {
+		dialOpts = append(dialOpts, redis.DialUseTLS(true), redis.DialTLSConfig(o.TLSConfig))
+	}
+	if o.Username == "" {
+		dialOpts = append(dialOpts, redis.DialPassword(o.Password), redis.DialDatabase(o.Database))
+	}
+
+	return func() (redis.Conn, error) {
+		c, err := redis.Dial("tcp", host, dialOpts...)
+		if err != nil || o.Username == "" {
+			return c, err
 		}
-		return err
+
+		// ACL authentication is not supported by the dialer, so we authenticate and select the db ourselves
+		if _, err = c.Do("AUTH", o.Username, o.Password); err != nil {
+			c.Close()
+			return nil, err
+		}
+		if o.Database != 0 {
+			if _, err = c.Do("SELECT", o.Database); err != nil {
+				c.Close()
+				return nil, err
+			}
+		}
+		return c, nil
 	}
-	return &singleHostPool{ret}
 }
 
-type multiHostPool struct {
-	sync.Mutex
-	pools map[string]*redis.Pool
-	hosts []string
+// newPool creates a connection pool to a single host
+func (o ClientOptions) newPool(host string) *redis.Pool {
+	return o.newDialPool(o.dialFunc(host))
 }
 
-func (p *multiHostPool) Close() error {
-	p.Lock()
-	defer p.Unlock()
-	for _, pool := range p.pools {
-		pool.Close()
+// newDialPool creates a connection pool sized with the options, making new connections with dial
+func (o ClientOptions) newDialPool(dial func() (redis.Conn, error)) *redis.Pool {
+	ret := redis.NewPool(dial, maxConns)
+	if o.PoolSize > 0 {
+		ret.MaxIdle = o.PoolSize
+		ret.MaxActive = o.PoolSize
+		ret.Wait = true
+	}
+	ret.IdleTimeout = o.IdleTimeout
+	ret.TestOnBorrow = func(c redis.Conn, t time.Time) (err error) {
+		if time.Since(t) > time.Second {
+			_, err = c.Do("PING")
+		}
+		return err
 	}
-	return nil
+	return ret
 }
 
-func NewMultiHostPool(hosts []string) ConnPool {
-	return &multiHostPool{
-		pools: make(map[string]*redis.Pool, len(hosts)),
-		hosts: hosts,
+// NewPool creates a connection pool with the given options. Addr can be a single host:port pair,
+// or a comma separated list of host:port,host:port... in which case connections are made to random hosts
+func NewPool(addr string, opts ClientOptions) ConnPool {
+	addrs := strings.Split(addr, ",")
+	if len(addrs) == 1 {
+		return NewSingleHostPoolOptions(addrs[0], opts)
 	}
+	return NewMultiHostPoolOptions(addrs, opts)
 }
 
-func (p *multiHostPool) Get() redis.Conn {
-	p.Lock()
-	defer p.Unlock()
-	host := p.hosts[rand.Intn(len(p.hosts))]
-	pool, found := p.pools[host]
-	if !found {
-		pool = redis.NewPool(func() (redis.Conn, error) {
-			// TODO: Add timeouts. and 2 separate pools for indexing and querying, with different timeouts
-			return redis.Dial("tcp", host)
-		}, maxConns)
-		pool.TestOnBorrow = func(c redis.Conn, t time.Time) error {
-			if time.Since(t).Seconds() > 1 {
-				_, err := c.Do("PING")
-				return err
-			}
-			return nil
-		}
+type singleHostPool struct {
+	*redis.Pool
+}
 
-		p.pools[host] = pool
-	}
-	return pool.Get()
+func NewSingleHostPool(host string) ConnPool {
+	return NewSingleHostPoolOptions(host, DefaultClientOptions)
+}
 
+// NewSingleHostPoolOptions creates a pool of connections to a single host, dialed with the given options
+func NewSingleHostPoolOptions(host string, opts ClientOptions) ConnPool {
+	return &singleHostPool{opts.newPool(host)}
 }
@@ -0,0 +1,100 @@
+package redisearch
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readCommand reads a single RESP command sent by a client
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, n)
+	for i := range args {
+		if _, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimSpace(arg)
+	}
+	return args, nil
+}
+
+// serveCommands accepts a single connection, records every command it receives and replies +OK
+func serveCommands(t *testing.T, l net.Listener, commands chan<- string) {
+	conn, err := l.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		commands <- strings.Join(args, " ")
+		conn.Write([]byte("+OK\r\n"))
+	}
+}
+
+func TestClientOptionsDial(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ClientOptions
+		want []string
+	}{
+		{"default", DefaultClientOptions, []string{"PING"}},
+		{"password", ClientOptions{Password: "secret", Database: 2}, []string{"AUTH secret", "SELECT 2", "PING"}},
+		{"acl", ClientOptions{Username: "user", Password: "secret", Database: 3}, []string{"AUTH user secret", "SELECT 3", "PING"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Skip("cannot listen: ", err)
+			}
+			defer l.Close()
+			commands := make(chan string, 10)
+			go serveCommands(t, l, commands)
+
+			tt.opts.ConnectTimeout = time.Second
+			pool := NewSingleHostPoolOptions(l.Addr().String(), tt.opts)
+			defer pool.Close()
+			conn := pool.Get()
+			_, err = conn.Do("PING")
+			assert.Nil(t, err)
+			conn.Close()
+
+			for _, want := range tt.want {
+				assert.Equal(t, want, <-commands)
+			}
+		})
+	}
+}
+
+func TestSplitClient(t *testing.T) {
+	queryPool := &fakePool{reply: func(string) error { return nil }}
+	indexPool := &fakePool{reply: func(string) error { return nil }}
+	c := NewSplitClient(queryPool, indexPool, "idx")
+
+	assert.Nil(t, c.Index(NewDocument("doc1", 1)))
+	assert.Equal(t, []string{"doc1"}, indexPool.sent)
+	assert.Empty(t, queryPool.sent)
+
+	c = NewClientFromPool(queryPool, "idx")
+	assert.Nil(t, c.Index(NewDocument("doc2", 1)))
+	assert.Equal(t, []string{"doc2"}, queryPool.sent)
+}
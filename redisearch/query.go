@@ -81,6 +81,9 @@ func (t tagFilter) serialize() string {
 type Query struct {
 	Raw string
 
+	// Node is an optional query tree, rendered after Raw
+	Node Node
+
 	Paging Paging
 	Flags  Flag
 	Slop   int
@@ -88,6 +91,7 @@ type Query struct {
 	// TagFilters are AND-ed
 	tagFilters    []tagFilter
 	Filters       []Predicate
+	GeoFilters    []Geo
 	InKeys        []string
 	InFields      []string
 	ReturnFields  []string
@@ -124,11 +128,28 @@ func NewQuery(raw string) *Query {
 	}
 }
 
-func (q Query) serialize() redis.Args {
+// NewQueryNode creates a new query whose text is rendered from the given query tree
+func NewQueryNode(n Node) *Query {
+	q := NewQuery("")
+	q.Node = n
+	return q
+}
+
+// text renders the query string: Raw, the query tree and all filters, AND-ed together
+func (q Query) text() string {
 	var raws []string
 	if q.Raw != "" {
 		raws = append(raws, q.Raw)
 	}
+	if q.Node != nil {
+		ns := q.Node.String()
+		if q.Raw != "" || len(q.tagFilters) > 0 || len(q.Filters) > 0 {
+			ns = group(q.Node)
+		}
+		if ns != "" {
+			raws = append(raws, ns)
+		}
+	}
 	for _, tf := range q.tagFilters {
 		if tfs := tf.serialize(); tfs != "" {
 			raws = append(raws, tfs)
@@ -139,7 +160,11 @@ func (q Query) serialize() redis.Args {
 			raws = append(raws, nfs)
 		}
 	}
-	args := redis.Args{strings.Join(raws, " "), "LIMIT", q.Paging.Offset, q.Paging.Num}
+	return strings.Join(raws, " ")
+}
+
+func (q Query) serialize() redis.Args {
+	args := redis.Args{q.text(), "LIMIT", q.Paging.Offset, q.Paging.Num}
 	if q.Flags&QueryVerbatim != 0 {
 		args = args.Add("VERBATIM")
 	}
@@ -158,6 +183,14 @@ func (q Query) serialize() redis.Args {
 		args = args.Add("WITHSCORES")
 	}
 
+	for _, gf := range q.GeoFilters {
+		unit := gf.Unit
+		if unit == "" {
+			unit = Kilometers
+		}
+		args = args.Add("GEOFILTER", gf.Field, formatFloat(gf.Lon), formatFloat(gf.Lat), formatFloat(gf.Radius), string(unit))
+	}
+
 	if q.InKeys != nil {
 		args = args.Add("INKEYS", len(q.InKeys))
 		args = args.AddFlat(q.InKeys)
@@ -224,6 +257,24 @@ func (q *Query) AddPredicate(p Predicate) *Query {
 	return q
 }
 
+// SetNode sets the query tree that is rendered into the query text, alongside Raw and the filters
+func (q *Query) SetNode(n Node) *Query {
+	q.Node = n
+	return q
+}
+
+// GeoFilter adds a GEOFILTER, limiting the results to documents whose geo field is within radius of the given point
+func (q *Query) GeoFilter(field string, lon, lat, radius float64, unit GeoUnit) *Query {
+	q.GeoFilters = append(q.GeoFilters, Geo{
+		Field:  field,
+		Lon:    lon,
+		Lat:    lat,
+		Radius: radius,
+		Unit:   unit,
+	})
+	return q
+}
+
 // AddTagFilter adds a tag filter on the specified tag, filtering the specified values with OR
 func (q *Query) AddTagFilter(tagFieldName string, values []string) *Query {
 	q.tagFilters = append(q.tagFilters, tagFilter{
@@ -0,0 +1,263 @@
+package redisearch
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Node is a single node in a query tree. Nodes render themselves to the RediSearch
+// query syntax, escaping any user supplied values.
+// See https://oss.redislabs.com/redisearch/Query_Syntax.html
+type Node interface {
+	String() string
+}
+
+// GeoUnit is the distance unit used by geo queries
+type GeoUnit string
+
+// Geo distance units
+const (
+	Meters     GeoUnit = "m"
+	Kilometers GeoUnit = "km"
+	Miles      GeoUnit = "mi"
+	Feet       GeoUnit = "ft"
+)
+
+// Term matches a single (escaped) word
+type Term string
+
+// Phrase matches the given words in order, next to each other
+type Phrase []string
+
+// Prefix matches all the words starting with the given prefix
+type Prefix string
+
+// Fuzzy matches words within the given Levenshtein distance (1-3) of Term
+type Fuzzy struct {
+	Term     string
+	Distance int
+}
+
+// Intersect matches documents that match all of its children
+type Intersect []Node
+
+// Union matches documents that match any of its children
+type Union []Node
+
+// Not matches documents that do not match its child
+type Not struct {
+	Node Node
+}
+
+// Optional boosts documents matching its child, without requiring them to match
+type Optional struct {
+	Node Node
+}
+
+// FieldScope limits its child to the given fields
+type FieldScope struct {
+	Fields []string
+	Node   Node
+}
+
+// NumericRange matches documents whose numeric field is between Min and Max.
+// Use "-inf" and "+inf" for open ranges
+type NumericRange struct {
+	Field        string
+	Min, Max     interface{}
+	ExclusiveMin bool
+	ExclusiveMax bool
+}
+
+// TagSet matches documents whose tag field contains any of the given tags
+type TagSet struct {
+	Field string
+	Tags  []string
+}
+
+// Geo matches documents whose geo field is within Radius of the given point
+type Geo struct {
+	Field  string
+	Lon    float64
+	Lat    float64
+	Radius float64
+	Unit   GeoUnit
+}
+
+// Attribute is a single query attribute, e.g. $weight or $slop
+type Attribute struct {
+	Name  string
+	Value string
+}
+
+// Attributes attaches query attributes to its child, rendered as child=>{$name: value; ...}
+type Attributes struct {
+	Node  Node
+	Attrs []Attribute
+}
+
+// escapeTerm escapes every rune that the query parser would treat as a separator or operator
+func escapeTerm(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// group renders a child node, wrapping it in parentheses if it has more than one operand
+func group(n Node) string {
+	switch v := n.(type) {
+	case Intersect:
+		if len(v) > 1 {
+			return "(" + v.String() + ")"
+		}
+	case Union:
+		if len(v) > 1 {
+			return "(" + v.String() + ")"
+		}
+	}
+	return n.String()
+}
+
+func (t Term) String() string {
+	return escapeTerm(string(t))
+}
+
+func (p Phrase) String() string {
+	words := make([]string, len(p))
+	for i, w := range p {
+		words[i] = escapeTerm(w)
+	}
+	return `"` + strings.Join(words, " ") + `"`
+}
+
+func (p Prefix) String() string {
+	return escapeTerm(string(p)) + "*"
+}
+
+func (f Fuzzy) String() string {
+	d := f.Distance
+	if d < 1 {
+		d = 1
+	} else if d > 3 {
+		d = 3
+	}
+	pct := strings.Repeat("%", d)
+	return pct + escapeTerm(f.Term) + pct
+}
+
+func (n Intersect) String() string {
+	parts := make([]string, 0, len(n))
+	for _, c := range n {
+		if s := group(c); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (n Union) String() string {
+	parts := make([]string, 0, len(n))
+	for _, c := range n {
+		if s := group(c); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+func (n Not) String() string {
+	return "-" + group(n.Node)
+}
+
+func (n Optional) String() string {
+	return "~" + group(n.Node)
+}
+
+func (n FieldScope) String() string {
+	fields := make([]string, len(n.Fields))
+	for i, f := range n.Fields {
+		fields[i] = escapeTerm(f)
+	}
+	return "@" + strings.Join(fields, "|") + ":" + group(n.Node)
+}
+
+// formatFloat formats a number without exponent notation, which the query parser does not read
+// consistently. Infinities are formatted as -inf and +inf
+func formatFloat(f float64) string {
+	if math.IsInf(f, -1) {
+		return negInf
+	}
+	if math.IsInf(f, 1) {
+		return posInf
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// formatBound formats a range bound, which is either a number or a string such as +inf
+func formatBound(v interface{}) string {
+	switch f := v.(type) {
+	case float64:
+		return formatFloat(f)
+	case float32:
+		if math.IsInf(float64(f), 0) {
+			return formatFloat(float64(f))
+		}
+		return strconv.FormatFloat(float64(f), 'f', -1, 32)
+	}
+	return fmt.Sprint(v)
+}
+
+func (n NumericRange) String() string {
+	min := formatBound(n.Min)
+	if n.ExclusiveMin {
+		min = "(" + min
+	}
+	max := formatBound(n.Max)
+	if n.ExclusiveMax {
+		max = "(" + max
+	}
+	return fmt.Sprintf("@%s:[%s %s]", escapeTerm(n.Field), min, max)
+}
+
+func (n TagSet) String() string {
+	tags := make([]string, len(n.Tags))
+	for i, t := range n.Tags {
+		tags[i] = escapeTerm(t)
+	}
+	return fmt.Sprintf("@%s:{%s}", escapeTerm(n.Field), strings.Join(tags, "|"))
+}
+
+func (n Geo) String() string {
+	unit := n.Unit
+	if unit == "" {
+		unit = Kilometers
+	}
+	return fmt.Sprintf("@%s:[%s %s %s %s]", escapeTerm(n.Field), formatFloat(n.Lon), formatFloat(n.Lat), formatFloat(n.Radius), unit)
+}
+
+func (n Attributes) String() string {
+	attrs := make([]string, len(n.Attrs))
+	for i, a := range n.Attrs {
+		attrs[i] = fmt.Sprintf("$%s: %s", a.Name, a.Value)
+	}
+	return group(n.Node) + "=>{" + strings.Join(attrs, "; ") + "}"
+}
+
+// Node returns the query tree equivalent of the predicate
+func (p Predicate) Node() NumericRange {
+	return NumericRange{
+		Field:        p.Property,
+		Min:          p.min,
+		Max:          p.max,
+		ExclusiveMin: !p.minInclusive,
+		ExclusiveMax: !p.maxInclusive,
+	}
+}
@@ -0,0 +1,72 @@
+package redisearch
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestNodeString(t *testing.T) {
+	tests := []struct {
+		name string
+		node Node
+		want string
+	}{
+		{"term", Term("hello"), "hello"},
+		{"escaped term", Term("foo-bar baz"), `foo\-bar\ baz`},
+		{"phrase", Phrase{"hello", "world"}, `"hello world"`},
+		{"prefix", Prefix("hel"), "hel*"},
+		{"fuzzy", Fuzzy{Term: "hello", Distance: 2}, "%%hello%%"},
+		{"fuzzy clamped", Fuzzy{Term: "hello"}, "%hello%"},
+		{"intersect", Intersect{Term("hello"), Term("world")}, "hello world"},
+		{"union", Union{Term("hello"), Term("hallo")}, "hello|hallo"},
+		{"union of intersects", Union{Intersect{Term("a"), Term("b")}, Term("c")}, "(a b)|c"},
+		{"intersect of unions", Intersect{Union{Term("a"), Term("b")}, Term("c")}, "(a|b) c"},
+		{"not", Not{Term("hello")}, "-hello"},
+		{"not group", Not{Union{Term("a"), Term("b")}}, "-(a|b)"},
+		{"optional", Optional{Term("hello")}, "~hello"},
+		{"field scope", FieldScope{Fields: []string{"title"}, Node: Term("hello")}, "@title:hello"},
+		{"multi field scope", FieldScope{Fields: []string{"title", "body"}, Node: Intersect{Term("a"), Term("b")}}, "@title|body:(a b)"},
+		{"numeric range", NumericRange{Field: "price", Min: 10, Max: posInf, ExclusiveMin: true}, "@price:[(10 +inf]"},
+		{"large numeric range", NumericRange{Field: "n", Min: -1e21, Max: 1.5e-7}, "@n:[-1000000000000000000000 0.00000015]"},
+		{"infinite numeric range", NumericRange{Field: "n", Min: math.Inf(-1), Max: float32(2.5)}, "@n:[-inf 2.5]"},
+		{"tag set", TagSet{Field: "tags", Tags: []string{"foo bar", "baz"}}, `@tags:{foo\ bar|baz}`},
+		{"geo", Geo{Field: "loc", Lon: -122.41, Lat: 37.77, Radius: 5, Unit: Miles}, "@loc:[-122.41 37.77 5 mi]"},
+		{"geo default unit", Geo{Field: "loc", Lon: 1, Lat: 2, Radius: 3}, "@loc:[1 2 3 km]"},
+		{"geo small radius", Geo{Field: "loc", Lon: 1e-7, Lat: 2, Radius: 1e21, Unit: Meters}, "@loc:[0.0000001 2 1000000000000000000000 m]"},
+		{"predicate", GreaterThan("n", 1).Node(), "@n:[(1 +inf]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.node.String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuerySerializeNode(t *testing.T) {
+	q := NewQueryNode(Union{Term("a"), Term("b")})
+	if got := q.serialize()[0]; got != "a|b" {
+		t.Errorf("serialize() = %v, want %v", got, "a|b")
+	}
+
+	q = NewQuery("hello").SetNode(Union{Term("a"), Term("b")}).AddPredicate(Equals("n", 1))
+	if got := q.serialize()[0]; got != "hello (a|b) @n:[1 1]" {
+		t.Errorf("serialize() = %v, want %v", got, "hello (a|b) @n:[1 1]")
+	}
+}
+
+func TestQueryGeoFilter(t *testing.T) {
+	args := NewQuery("*").GeoFilter("loc", 1e-7, 37.77, 1e21, Meters).serialize()
+	want := fmt.Sprint("GEOFILTER", "loc", "0.0000001", "37.77", "1000000000000000000000", "m")
+	for i, arg := range args {
+		if arg == "GEOFILTER" {
+			if got := fmt.Sprint(args[i : i+6]...); got != want {
+				t.Errorf("serialize() = %v, want %v", got, want)
+			}
+			return
+		}
+	}
+	t.Errorf("serialize() = %v, want a GEOFILTER", args)
+}
@@ -0,0 +1,465 @@
+package redisearch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseError is returned by ParseQuery when the query text is not valid RediSearch syntax
+type ParseError struct {
+	Query string
+	// Pos is the rune offset in Query where the error was detected
+	Pos int
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("Syntax error at offset %d in query %q: %s", e.Pos, e.Query, e.Msg)
+}
+
+// queryParser is a recursive descent parser over the runes of a query string
+type queryParser struct {
+	query string
+	in    []rune
+	pos   int
+}
+
+// ParseQuery parses a RediSearch query string into a query tree made of the same node types
+// used to build queries, so that it can be inspected or rewritten and rendered back with String().
+//
+// Rendering a parsed tree yields a canonical form of the query: redundant parentheses and
+// whitespace are dropped and groups are wrapped explicitly, so the result of rendering a tree
+// built with this package parses back to an identical string.
+func ParseQuery(s string) (Node, error) {
+	p := &queryParser{query: s, in: []rune(s)}
+	n, err := p.parseIntersect()
+	if err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q", p.peek())
+	}
+	return n, nil
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	return &ParseError{Query: p.query, Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *queryParser) eof() bool {
+	return p.pos >= len(p.in)
+}
+
+func (p *queryParser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.in[p.pos]
+}
+
+// isSeparator reports whether r is ignored between terms, the way the server tokenizer does
+func isSeparator(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(",.<>'!#$^&;/?+", r)
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (p *queryParser) skipSpace() {
+	for !p.eof() && isSeparator(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *queryParser) skipWhitespace() {
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *queryParser) expect(r rune) error {
+	p.skipWhitespace()
+	if p.peek() != r {
+		if p.eof() {
+			return p.errorf("expected %q, got end of query", r)
+		}
+		return p.errorf("expected %q, got %q", r, p.peek())
+	}
+	p.pos++
+	return nil
+}
+
+// readWord reads a run of word runes and backslash escapes, returning the unescaped word
+func (p *queryParser) readWord() (string, error) {
+	var b strings.Builder
+	for !p.eof() {
+		r := p.peek()
+		if r == '\\' {
+			p.pos++
+			if p.eof() {
+				return "", p.errorf("dangling escape")
+			}
+			b.WriteRune(p.peek())
+			p.pos++
+			continue
+		}
+		if !isWordRune(r) {
+			break
+		}
+		b.WriteRune(r)
+		p.pos++
+	}
+	return b.String(), nil
+}
+
+// parseIntersect parses a sequence of unions until the end of the query or a closing parenthesis
+func (p *queryParser) parseIntersect() (Node, error) {
+	var ret Intersect
+	for {
+		p.skipSpace()
+		if p.eof() || p.peek() == ')' {
+			break
+		}
+		n, err := p.parseUnion()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, n)
+	}
+	if len(ret) == 1 {
+		return ret[0], nil
+	}
+	return ret, nil
+}
+
+func (p *queryParser) parseUnion() (Node, error) {
+	n, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	ret := Union{n}
+	for {
+		p.skipWhitespace()
+		if p.peek() != '|' {
+			break
+		}
+		p.pos++
+		p.skipSpace()
+		if n, err = p.parseUnary(); err != nil {
+			return nil, err
+		}
+		ret = append(ret, n)
+	}
+	if len(ret) == 1 {
+		return ret[0], nil
+	}
+	return ret, nil
+}
+
+func (p *queryParser) parseUnary() (Node, error) {
+	switch p.peek() {
+	case '-':
+		p.pos++
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{n}, nil
+	case '~':
+		p.pos++
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Optional{n}, nil
+	}
+
+	n, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+	return p.parseAttributes(n)
+}
+
+func (p *queryParser) parseAtom() (Node, error) {
+	if p.eof() {
+		return nil, p.errorf("unexpected end of query")
+	}
+	switch r := p.peek(); r {
+	case '(':
+		p.pos++
+		n, err := p.parseIntersect()
+		if err != nil {
+			return nil, err
+		}
+		if g, ok := n.(Intersect); ok && len(g) == 0 {
+			// the server rejects empty groups, and they cannot be rendered
+			return nil, p.errorf("empty group")
+		}
+		if err = p.expect(')'); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case '"':
+		return p.parsePhrase()
+	case '@':
+		return p.parseField()
+	case '%':
+		return p.parseFuzzy()
+	case '*':
+		p.pos++
+		return Prefix(""), nil
+	default:
+		if r != '\\' && !isWordRune(r) {
+			return nil, p.errorf("unexpected %q", r)
+		}
+		w, err := p.readWord()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() == '*' {
+			p.pos++
+			return Prefix(w), nil
+		}
+		return Term(w), nil
+	}
+}
+
+func (p *queryParser) parsePhrase() (Node, error) {
+	p.pos++
+	var ret Phrase
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return nil, p.errorf("unterminated phrase")
+		}
+		if p.peek() == '"' {
+			p.pos++
+			return ret, nil
+		}
+		if p.peek() != '\\' && !isWordRune(p.peek()) {
+			return nil, p.errorf("unexpected %q in phrase", p.peek())
+		}
+		w, err := p.readWord()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, w)
+	}
+}
+
+func (p *queryParser) parseFuzzy() (Node, error) {
+	d := 0
+	for p.peek() == '%' {
+		p.pos++
+		d++
+	}
+	if d > 3 {
+		return nil, p.errorf("fuzzy distance %d is above 3", d)
+	}
+	w, err := p.readWord()
+	if err != nil {
+		return nil, err
+	}
+	if w == "" {
+		return nil, p.errorf("expected a term after %q", strings.Repeat("%", d))
+	}
+	for i := 0; i < d; i++ {
+		if p.peek() != '%' {
+			return nil, p.errorf("unbalanced fuzzy term %q", w)
+		}
+		p.pos++
+	}
+	return Fuzzy{Term: w, Distance: d}, nil
+}
+
+func (p *queryParser) parseField() (Node, error) {
+	p.pos++
+	var fields []string
+	for {
+		f, err := p.readWord()
+		if err != nil {
+			return nil, err
+		}
+		if f == "" {
+			return nil, p.errorf("expected a field name")
+		}
+		fields = append(fields, f)
+		if p.peek() != '|' {
+			break
+		}
+		p.pos++
+	}
+	if p.peek() != ':' {
+		return nil, p.errorf("expected ':' after field name")
+	}
+	p.pos++
+	p.skipWhitespace()
+
+	switch p.peek() {
+	case '{':
+		if len(fields) > 1 {
+			return nil, p.errorf("tag filters take a single field")
+		}
+		return p.parseTags(fields[0])
+	case '[':
+		if len(fields) > 1 {
+			return nil, p.errorf("range filters take a single field")
+		}
+		return p.parseRange(fields[0])
+	}
+
+	n, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return FieldScope{Fields: fields, Node: n}, nil
+}
+
+func (p *queryParser) parseTags(field string) (Node, error) {
+	p.pos++
+	ret := TagSet{Field: field}
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return nil, p.errorf("unterminated tag list")
+		}
+		r := p.peek()
+		p.pos++
+		switch r {
+		case '\\':
+			if p.eof() {
+				return nil, p.errorf("dangling escape")
+			}
+			b.WriteRune(p.peek())
+			p.pos++
+		case '|', '}':
+			tag := strings.TrimSpace(b.String())
+			if tag == "" {
+				return nil, p.errorf("empty tag")
+			}
+			ret.Tags = append(ret.Tags, tag)
+			b.Reset()
+			if r == '}' {
+				return ret, nil
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+func (p *queryParser) parseRange(field string) (Node, error) {
+	p.pos++
+	start := p.pos
+	for !p.eof() && p.peek() != ']' {
+		p.pos++
+	}
+	if p.eof() {
+		return nil, p.errorf("unterminated range")
+	}
+	parts := strings.Fields(string(p.in[start:p.pos]))
+	p.pos++
+
+	switch len(parts) {
+	case 2:
+		ret := NumericRange{Field: field}
+		var err error
+		ret.Min, ret.ExclusiveMin, err = parseRangeValue(parts[0])
+		if err != nil {
+			return nil, p.errorf("invalid range minimum %q", parts[0])
+		}
+		ret.Max, ret.ExclusiveMax, err = parseRangeValue(parts[1])
+		if err != nil {
+			return nil, p.errorf("invalid range maximum %q", parts[1])
+		}
+		return ret, nil
+	case 4:
+		ret := Geo{Field: field, Unit: GeoUnit(strings.ToLower(parts[3]))}
+		var err error
+		if ret.Lon, err = strconv.ParseFloat(parts[0], 64); err != nil {
+			return nil, p.errorf("invalid longitude %q", parts[0])
+		}
+		if ret.Lat, err = strconv.ParseFloat(parts[1], 64); err != nil {
+			return nil, p.errorf("invalid latitude %q", parts[1])
+		}
+		if ret.Radius, err = strconv.ParseFloat(parts[2], 64); err != nil {
+			return nil, p.errorf("invalid radius %q", parts[2])
+		}
+		switch ret.Unit {
+		case Meters, Kilometers, Miles, Feet:
+		default:
+			return nil, p.errorf("invalid geo unit %q", parts[3])
+		}
+		return ret, nil
+	}
+	return nil, p.errorf("expected [min max] or [lon lat radius unit]")
+}
+
+// parseRangeValue parses a numeric range boundary, keeping infinite boundaries as strings
+func parseRangeValue(s string) (v interface{}, exclusive bool, err error) {
+	if strings.HasPrefix(s, "(") {
+		exclusive = true
+		s = s[1:]
+	}
+	switch strings.ToLower(s) {
+	case "-inf":
+		return negInf, exclusive, nil
+	case "inf", "+inf":
+		return posInf, exclusive, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, exclusive, err
+}
+
+// parseAttributes parses an optional =>{$name: value; ...} suffix and attaches it to n
+func (p *queryParser) parseAttributes(n Node) (Node, error) {
+	save := p.pos
+	p.skipWhitespace()
+	if !strings.HasPrefix(string(p.in[p.pos:]), "=>") {
+		p.pos = save
+		return n, nil
+	}
+	p.pos += 2
+	if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+	ret := Attributes{Node: n}
+	for {
+		p.skipWhitespace()
+		if p.peek() == '}' {
+			p.pos++
+			break
+		}
+		if err := p.expect('$'); err != nil {
+			return nil, err
+		}
+		name, err := p.readWord()
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			return nil, p.errorf("expected an attribute name")
+		}
+		if err = p.expect(':'); err != nil {
+			return nil, err
+		}
+		p.skipWhitespace()
+		start := p.pos
+		for !p.eof() && p.peek() != ';' && p.peek() != '}' {
+			p.pos++
+		}
+		value := strings.TrimSpace(string(p.in[start:p.pos]))
+		if value == "" {
+			return nil, p.errorf("expected a value for $%s", name)
+		}
+		ret.Attrs = append(ret.Attrs, Attribute{Name: name, Value: value})
+		if p.peek() == ';' {
+			p.pos++
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,82 @@
+package redisearch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  Node
+	}{
+		{"term", "hello", Term("hello")},
+		{"intersect", "hello  world", Intersect{Term("hello"), Term("world")}},
+		{"union binds tighter", "a b|c", Intersect{Term("a"), Union{Term("b"), Term("c")}}},
+		{"group", "(a b)|c", Union{Intersect{Term("a"), Term("b")}, Term("c")}},
+		{"not optional", "-a ~b", Intersect{Not{Term("a")}, Optional{Term("b")}}},
+		{"phrase", `"hello world"`, Phrase{"hello", "world"}},
+		{"prefix", "hel*", Prefix("hel")},
+		{"wildcard", "*", Prefix("")},
+		{"fuzzy", "%%hello%%", Fuzzy{Term: "hello", Distance: 2}},
+		{"escaped", `foo\-bar\ baz`, Term("foo-bar baz")},
+		{"field scope", "@title|body:(a b)", FieldScope{Fields: []string{"title", "body"}, Node: Intersect{Term("a"), Term("b")}}},
+		{"tags", `@tags:{ foo\ bar | baz }`, TagSet{Field: "tags", Tags: []string{"foo bar", "baz"}}},
+		{"numeric", "@price:[(10 +inf]", NumericRange{Field: "price", Min: 10.0, Max: posInf, ExclusiveMin: true}},
+		{"geo", "@loc:[-122.41 37.77 5 mi]", Geo{Field: "loc", Lon: -122.41, Lat: 37.77, Radius: 5, Unit: Miles}},
+		{"attributes", "(a b)=>{$weight: 2; $slop: 1}", Attributes{
+			Node:  Intersect{Term("a"), Term("b")},
+			Attrs: []Attribute{{"weight", "2"}, {"slop", "1"}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseQuery() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryRoundTrip(t *testing.T) {
+	q := NewQueryNode(Intersect{
+		Union{Phrase{"hello", "world"}, Prefix("hel")},
+		Not{FieldScope{Fields: []string{"title"}, Node: Fuzzy{Term: "wrld", Distance: 1}}},
+		Optional{Term("foo-bar")},
+		Attributes{Node: Intersect{Term("a"), Term("b")}, Attrs: []Attribute{{"slop", "1"}}},
+	}).AddTagFilter("tags", []string{"foo bar"}).AddPredicate(InRange("n", 1, 5, true, false))
+	q.Node = Intersect{q.Node, Geo{Field: "loc", Lon: 1.5, Lat: 2, Radius: 10, Unit: Meters}}
+
+	raw := q.serialize()[0].(string)
+	n, err := ParseQuery(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := n.String(); got != raw {
+		t.Errorf("round trip = %v, want %v", got, raw)
+	}
+
+	for _, s := range []string{"~(a b)", "-(a|b)", "@title:(a b)"} {
+		n, err := ParseQuery(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := n.String(); got != s {
+			t.Errorf("round trip = %v, want %v", got, s)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, s := range []string{"(a b", "a)", `"a b`, "%%a%", "@title", "@n:[1]", "@t:{a", "@l:[1 2 3 lightyears]", "a=>{weight: 1}", "()", "~()", "-()", "@title:( )"} {
+		_, err := ParseQuery(s)
+		if _, ok := err.(*ParseError); !ok {
+			t.Errorf("ParseQuery(%q) error = %v, want a *ParseError", s, err)
+		}
+	}
+}
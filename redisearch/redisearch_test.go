@@ -1,6 +1,7 @@
 package redisearch
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -58,6 +59,8 @@ func TestClient(t *testing.T) {
 		} else {
 			assert.Equal(t, 100, len(merr))
 			assert.NotEmpty(t, merr)
+			assert.Equal(t, docs[42].Id, merr[42].(*DocumentError).Id)
+			assert.Equal(t, 100, len(merr.FailedIDs()))
 			//fmt.Println("Got errors: ", merr)
 		}
 	}
@@ -114,10 +117,14 @@ func TestInfo(t *testing.T) {
 		AddField(NewTextField("foo")).
 		AddField(NewSortableNumericField("bar"))
 	c.Drop()
+	_, err := c.Info()
+	assert.True(t, errors.Is(err, ErrIndexNotFound))
 	assert.Nil(t, c.CreateIndex(sc))
+	assert.True(t, errors.Is(c.CreateIndex(sc), ErrIndexExists))
 
 	info, err := c.Info()
 	assert.Nil(t, err)
+	assert.Equal(t, *sc, info.Schema)
 	fmt.Printf("%v\n", info)
 }
 
@@ -651,3 +658,99 @@ func TestPhonetic(t *testing.T) {
 	assertNumResults("jon", 2)
 
 }
+
+func TestAggregate(t *testing.T) {
+	c := createClient("testung")
+	defer c.Close()
+
+	sc := NewSchema(DefaultOptions).
+		AddField(NewTextField("foo")).
+		AddField(NewTagFieldOptions("color", TagFieldOptions{Separator: ',', Sortable: true})).
+		AddField(NewSortableNumericField("bar"))
+	c.Drop()
+	assert.Nil(t, c.CreateIndex(sc))
+
+	docs := make([]Document, 100)
+	for i := 0; i < 100; i++ {
+		color := "red"
+		if i%2 == 0 {
+			color = "blue"
+		}
+		docs[i] = NewDocument(fmt.Sprintf("doc%d", i), 1).Set("foo", "hello world").Set("color", color).Set("bar", i)
+	}
+	assert.Nil(t, c.Index(docs...))
+
+	q := NewAggregateQuery(NewQuery("hello")).
+		GroupBy([]string{"color"}, ReduceCount().As("num"), ReduceMax("bar").As("top")).
+		SortBy(0, SortingKey{Field: "color", Ascending: true})
+	rows, total, err := c.Aggregate(q)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, 2, len(rows))
+	assert.Equal(t, "blue", rows[0]["color"])
+	assert.Equal(t, "50", rows[0]["num"])
+	assert.Equal(t, "98", rows[0]["top"])
+	assert.Equal(t, "red", rows[1]["color"])
+	assert.Equal(t, "99", rows[1]["top"])
+}
+
+func TestAggregateCursor(t *testing.T) {
+	c := createClient("testung")
+	defer c.Close()
+
+	sc := NewSchema(DefaultOptions).
+		AddField(NewTextField("foo")).
+		AddField(NewSortableNumericField("bar"))
+	c.Drop()
+	assert.Nil(t, c.CreateIndex(sc))
+
+	docs := make([]Document, 100)
+	for i := 0; i < 100; i++ {
+		docs[i] = NewDocument(fmt.Sprintf("doc%d", i), 1).Set("foo", "hello world").Set("bar", i)
+	}
+	assert.Nil(t, c.Index(docs...))
+
+	cur := c.AggregateCursor(NewAggregateQuery(NewQuery("hello")).Load("bar"), CursorOptions{Count: 30})
+	defer cur.Close()
+
+	batches, rows := 0, 0
+	for cur.Next() {
+		batches++
+		rows += len(cur.Rows())
+	}
+	assert.Nil(t, cur.Err())
+	assert.Equal(t, 100, rows)
+	assert.True(t, batches >= 4)
+
+	// closing an exhausted cursor is a no-op
+	assert.Nil(t, cur.Close())
+	assert.False(t, cur.Next())
+}
+
+func TestGeo(t *testing.T) {
+	c := createClient("testung")
+	defer c.Close()
+
+	sc := NewSchema(DefaultOptions).
+		AddField(NewTextField("name")).
+		AddField(NewGeoField("location"))
+	c.Drop()
+	assert.Nil(t, c.CreateIndex(sc))
+
+	doc1 := NewDocument("doc1", 1).Set("name", "coffee shop").SetGeo("location", -122.4194, 37.7749)
+	doc2 := NewDocument("doc2", 1).Set("name", "coffee bar").SetGeo("location", -73.9857, 40.7484)
+	assert.Nil(t, c.Index(doc1, doc2))
+
+	docs, total, err := c.Search(NewQuery("coffee").GeoFilter("location", -122.41, 37.77, 10, Kilometers))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "doc1", docs[0].Id)
+
+	_, total, err = c.Search(NewQueryNode(Intersect{Term("coffee"), Geo{Field: "location", Lon: -73.98, Lat: 40.74, Radius: 5, Unit: Miles}}))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+
+	info, err := c.Info()
+	assert.Nil(t, err)
+	assert.Equal(t, GeoField, info.Schema.Fields[1].Type)
+}
@@ -0,0 +1,194 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// ReindexerOptions configure a Reindexer
+type ReindexerOptions struct {
+	// Version is the version of the new index, named <alias>_v<version>. If zero, the version
+	// following the one the alias points at is used, or 1 if there is none
+	Version int
+	// Bulk are the options of the bulk indexer streaming the documents into the new index.
+	// Documents are always indexed with Replace, so that retries are idempotent
+	Bulk BulkIndexerOptions
+	// DropOld drops the previous index once the alias points at the new one. Its documents are
+	// kept, since the new index uses the same keys
+	DropOld bool
+}
+
+// DefaultReindexerOptions are the default options for reindexing
+var DefaultReindexerOptions = ReindexerOptions{
+	Bulk: DefaultBulkIndexerOptions,
+}
+
+// DocumentSource streams the documents of a new index, calling add for every document.
+// It must return when all the documents were added, or when add returns an error
+type DocumentSource func(ctx context.Context, add func(Document) error) error
+
+// ReindexResult describes a completed reindexing
+type ReindexResult struct {
+	// Index is the name of the new index the alias points at
+	Index string
+	// Previous is the name of the index the alias pointed at before, empty if there was none
+	Previous string
+	// Indexed is the number of documents indexed
+	Indexed uint64
+}
+
+// Reindexer rebuilds an index without downtime, behind an alias. The queries use the alias as
+// the index name, while a new versioned index is created and filled from a DocumentSource,
+// checked, and swapped in atomically with FT.ALIASUPDATE:
+//
+//	c := redisearch.NewClient("localhost:6379", "products")
+//	res, err := c.NewReindexer(schema, redisearch.DefaultReindexerOptions).Run(source)
+//
+// The documents are stored once in hashes named by their id, which both versions share. Documents
+// changed while reindexing are thus seen by the old version as well, but only after it is reindexed
+type Reindexer struct {
+	client *Client
+	schema *Schema
+	opts   ReindexerOptions
+}
+
+// NewReindexer creates a reindexer for the alias that is the client's index name,
+// creating the new versions with the given schema
+func (i *Client) NewReindexer(s *Schema, opts ReindexerOptions) *Reindexer {
+	return &Reindexer{client: i, schema: s, opts: opts}
+}
+
+// versionedIndexName returns the name of a version of an aliased index
+func versionedIndexName(alias string, version int) string {
+	return alias + "_v" + strconv.Itoa(version)
+}
+
+// indexVersion returns the version of an aliased index, or false if it is not named as a version
+func indexVersion(alias, name string) (int, bool) {
+	prefix := alias + "_v"
+	if !strings.HasPrefix(name, prefix) {
+		return 0, false
+	}
+	v, err := strconv.Atoi(name[len(prefix):])
+	return v, err == nil && v > 0
+}
+
+// Run rebuilds the index, see RunContext
+func (r *Reindexer) Run(src DocumentSource) (*ReindexResult, error) {
+	return r.RunContext(context.Background(), src)
+}
+
+// RunContext creates the new version of the index, indexes the documents of the source in bulk,
+// checks that the new index has as many documents as were indexed, and points the alias at it.
+// If any step fails before the alias is updated, the new index is dropped, keeping the documents,
+// and the alias is left unchanged. The source must not return the same document twice, since the
+// count would not match
+func (r *Reindexer) RunContext(ctx context.Context, src DocumentSource) (*ReindexResult, error) {
+	alias := r.client.name
+	res := &ReindexResult{}
+
+	info, err := r.client.InfoContext(ctx)
+	switch {
+	case errors.Is(err, ErrIndexNotFound):
+	case err != nil:
+		return nil, err
+	case info.Name == alias:
+		return nil, fmt.Errorf("Index %s is not an alias", alias)
+	default:
+		res.Previous = info.Name
+	}
+
+	version := r.opts.Version
+	if version == 0 {
+		version = 1
+		if v, ok := indexVersion(alias, res.Previous); ok {
+			version = v + 1
+		}
+	}
+	res.Index = versionedIndexName(alias, version)
+	if res.Index == res.Previous {
+		return nil, fmt.Errorf("Alias %s already points at %s", alias, res.Index)
+	}
+
+	next := r.client.withName(res.Index)
+	if err := next.CreateIndexContext(ctx, r.schema); err != nil {
+		return nil, err
+	}
+
+	if res.Indexed, err = r.fill(ctx, next, src); err == nil {
+		err = r.verify(ctx, next, res.Indexed)
+	}
+	if err == nil {
+		if res.Previous == "" {
+			err = next.AddAliasContext(ctx, alias)
+		} else {
+			err = next.UpdateAliasContext(ctx, alias)
+		}
+	}
+	if err != nil {
+		// the context may be done, and the new index must not be left behind
+		if derr := next.drop(context.Background(), true); derr != nil {
+			return nil, MultiError{err, fmt.Errorf("Dropping %s: %s", res.Index, derr)}
+		}
+		return nil, err
+	}
+
+	if r.opts.DropOld && res.Previous != "" {
+		if err := r.client.withName(res.Previous).drop(ctx, true); err != nil {
+			return res, fmt.Errorf("Dropping %s: %s", res.Previous, err)
+		}
+	}
+	return res, nil
+}
+
+// fill indexes the documents of the source into the new index, and returns how many were indexed
+func (r *Reindexer) fill(ctx context.Context, next *Client, src DocumentSource) (uint64, error) {
+	opts := r.opts.Bulk
+	opts.Indexing.Replace = true
+	var failed atomic.Value
+	onResult := opts.OnResult
+	opts.OnResult = func(doc Document, err error) {
+		if err != nil {
+			failed.Store(&DocumentError{Id: doc.Id, Err: err})
+		}
+		if onResult != nil {
+			onResult(doc, err)
+		}
+	}
+
+	b := next.NewBulkIndexerContext(ctx, opts)
+	err := src(ctx, func(doc Document) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err, _ := failed.Load().(error); err != nil {
+			return err
+		}
+		return b.Add(doc)
+	})
+	if cerr := b.Close(); err == nil {
+		if err, _ = failed.Load().(error); err == nil {
+			err = cerr
+		}
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	return b.Stats().Indexed, err
+}
+
+// verify checks that the new index has all the indexed documents
+func (r *Reindexer) verify(ctx context.Context, next *Client, indexed uint64) error {
+	info, err := next.InfoContext(ctx)
+	if err != nil {
+		return err
+	}
+	if info.DocCount != indexed {
+		return fmt.Errorf("Index %s has %d documents, expected %d", next.name, info.DocCount, indexed)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package redisearch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAliases answers the commands of a reindexing over a products alias pointing at products_v1.
+// The new index reports lost documents less than were added
+type fakeAliases struct {
+	mu       sync.Mutex
+	commands []string
+	added    map[string]int
+	lost     int
+}
+
+func (f *fakeAliases) exec() FuncExecutor {
+	return FuncExecutor{DoFunc: func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		cmd := args[0].(string)
+		switch cmd {
+		case "FT.ADD":
+			f.added[args[1].(string)]++
+			return "OK", nil
+		case "FT.INFO":
+			name := args[1].(string)
+			if name == "products" {
+				name = "products_v1"
+			}
+			f.commands = append(f.commands, strings.TrimSpace(fmt.Sprintln(args...)))
+			return []interface{}{"index_name", name, "num_docs", int64(f.added[name] - f.lost)}, nil
+		}
+		f.commands = append(f.commands, strings.TrimSpace(fmt.Sprintln(args...)))
+		return "OK", nil
+	}}
+}
+
+func TestReindexer(t *testing.T) {
+	source := func(ctx context.Context, add func(Document) error) error {
+		for n := 0; n < 10; n++ {
+			if err := add(NewDocument(fmt.Sprintf("doc%d", n), 1).Set("title", "hello")); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	schema := NewSchema(DefaultOptions).AddField(NewTextField("title"))
+	opts := DefaultReindexerOptions
+	opts.Bulk.BatchSize = 3
+	opts.DropOld = true
+
+	f := &fakeAliases{added: map[string]int{}}
+	c := NewClientFromExecutor(f.exec(), "products")
+	res, err := c.NewReindexer(schema, opts).Run(source)
+	assert.Nil(t, err)
+	assert.Equal(t, &ReindexResult{Index: "products_v2", Previous: "products_v1", Indexed: 10}, res)
+	assert.Equal(t, []string{
+		"FT.INFO products",
+		"FT.CREATE products_v2 SCHEMA title TEXT",
+		"FT.INFO products_v2",
+		"FT.ALIASUPDATE products products_v2",
+		"FT.DROP products_v1 KEEPDOCS",
+	}, f.commands)
+
+	f = &fakeAliases{added: map[string]int{}, lost: 1}
+	c = NewClientFromExecutor(f.exec(), "products")
+	opts.Version = 7
+	_, err = c.NewReindexer(schema, opts).Run(source)
+	assert.NotNil(t, err)
+	assert.Equal(t, "Index products_v7 has 9 documents, expected 10", err.Error())
+	assert.Equal(t, "FT.DROP products_v7 KEEPDOCS", f.commands[len(f.commands)-1])
+	for _, cmd := range f.commands {
+		assert.False(t, strings.HasPrefix(cmd, "FT.ALIAS"), cmd)
+	}
+}
+
+func TestIndexVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		ok      bool
+	}{
+		{"products_v1", 1, true},
+		{"products_v12", 12, true},
+		{"products_v0", 0, false},
+		{"products_vx", 0, false},
+		{"products", 0, false},
+		{"orders_v3", 0, false},
+	}
+	for _, tt := range tests {
+		v, ok := indexVersion("products", tt.name)
+		assert.Equal(t, tt.ok, ok, tt.name)
+		if ok {
+			assert.Equal(t, tt.version, v, tt.name)
+		}
+	}
+	assert.Equal(t, "products_v3", versionedIndexName("products", 3))
+}
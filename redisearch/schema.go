@@ -1,5 +1,11 @@
 package redisearch
 
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
 // FieldType is an enumeration of field/property types
 type FieldType int
 
@@ -32,6 +38,8 @@ type TextFieldOptions struct {
 	NoStem       bool
 	NoIndex      bool
 	DMENPhonetic bool // phonetic indexing for english with double-metaphone algo
+	// PhoneticMatcher is the phonetic matcher, e.g. dm:fr for french. If set, it is used instead of DMENPhonetic
+	PhoneticMatcher string
 }
 
 // TagFieldOptions options for indexing tag fields
@@ -48,6 +56,11 @@ type NumericFieldOptions struct {
 	NoIndex  bool
 }
 
+// GeoFieldOptions Options for geo fields
+type GeoFieldOptions struct {
+	NoIndex bool
+}
+
 // NewTextField creates a new text field with the given weight
 func NewTextField(name string) Field {
 	return Field{
@@ -115,6 +128,21 @@ func NewSortableNumericField(name string) Field {
 	return f
 }
 
+// NewGeoField creates a new geo field with the given name
+func NewGeoField(name string) Field {
+	return Field{
+		Name: name,
+		Type: GeoField,
+	}
+}
+
+// NewGeoFieldOptions defines a geo field with additional options
+func NewGeoFieldOptions(name string, options GeoFieldOptions) Field {
+	f := NewGeoField(name)
+	f.Options = options
+	return f
+}
+
 // Schema represents an index schema Schema, or how the index would
 // treat documents sent to it.
 type Schema struct {
@@ -125,7 +153,8 @@ type Schema struct {
 // NewSchema creates a new Schema object
 func NewSchema(opts Options) *Schema {
 	return &Schema{
-		Fields: []Field{},
+		Fields:  []Field{},
+		Options: opts,
 	}
 }
 
@@ -137,3 +166,111 @@ func (m *Schema) AddField(f Field) *Schema {
 	m.Fields = append(m.Fields, f)
 	return m
 }
+
+// Limits of the number of indexed TEXT fields, NOINDEX fields are not counted. Every indexed TEXT
+// field gets a bit in the field mask of the index. The mask has 32 bits, unless the index is created
+// with MaxTextFields, or without field flags, where it has 128 bits on 64 bit servers
+const (
+	maxTextFields     = 32
+	maxWideTextFields = 128
+)
+
+// textFieldLimit returns the number of indexed TEXT fields an index with the options can have
+func (o Options) textFieldLimit() int {
+	if o.MaxTextFields || o.NoFieldFlags {
+		return maxWideTextFields
+	}
+	return maxTextFields
+}
+
+// phoneticMatchers are the phonetic matchers supported by the server
+var phoneticMatchers = map[string]bool{
+	"dm:en": true,
+	"dm:fr": true,
+	"dm:pt": true,
+	"dm:es": true,
+}
+
+// Validate checks the schema for the problems the server would reject it for, or that would make
+// fields useless, and returns all of them as a MultiError. CreateIndex validates the schema first
+func (m *Schema) Validate() error {
+	var errs MultiError
+	seen := make(map[string]bool, len(m.Fields))
+	textFields := 0
+	for n, f := range m.Fields {
+		if f.Name == "" {
+			errs = append(errs, fmt.Errorf("Invalid field %d: missing name", n))
+		} else if seen[f.Name] {
+			errs = append(errs, fieldError(f.Name, errors.New("defined twice")))
+		}
+		seen[f.Name] = true
+
+		for _, err := range validateField(f) {
+			errs = append(errs, fieldError(f.Name, err))
+		}
+		if f.Type == TextField && !canonicalField(f).NoIndex {
+			textFields++
+		}
+	}
+	if limit := m.Options.textFieldLimit(); textFields > limit {
+		err := fmt.Errorf("Too many TEXT fields: %d are indexed, at most %d can be", textFields, limit)
+		if limit < maxWideTextFields {
+			err = fmt.Errorf("%s without MaxTextFields", err)
+		}
+		errs = append(errs, err)
+	}
+	if errs != nil {
+		return errs
+	}
+	return nil
+}
+
+// validateField returns the problems of a single field
+func validateField(f Field) []error {
+	if _, found := fieldTypeNames[f.Type]; !found {
+		return []error{fmt.Errorf("unsupported type %d", f.Type)}
+	}
+	var errs []error
+	var noIndex, sortable bool
+	mismatch := fmt.Errorf("options of type %T do not apply to %s fields", f.Options, fieldTypeNames[f.Type])
+	switch opts := f.Options.(type) {
+	case nil:
+	case TextFieldOptions:
+		if f.Type != TextField {
+			return []error{mismatch}
+		}
+		w := float64(opts.Weight)
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			errs = append(errs, fmt.Errorf("invalid weight %v, must be a positive number", opts.Weight))
+		}
+		if opts.PhoneticMatcher != "" && !phoneticMatchers[opts.PhoneticMatcher] {
+			errs = append(errs, fmt.Errorf("unsupported phonetic matcher %q", opts.PhoneticMatcher))
+		}
+		noIndex, sortable = opts.NoIndex, opts.Sortable
+	case NumericFieldOptions:
+		if f.Type != NumericField {
+			return []error{mismatch}
+		}
+		noIndex, sortable = opts.NoIndex, opts.Sortable
+	case TagFieldOptions:
+		if f.Type != TagField {
+			return []error{mismatch}
+		}
+		// zero is the default separator
+		if sep := opts.Separator; sep != 0 && (sep <= ' ' || sep > '~') {
+			errs = append(errs, fmt.Errorf("invalid separator %q, must be a printable ASCII character", sep))
+		}
+		noIndex, sortable = opts.NoIndex, opts.Sortable
+	case GeoFieldOptions:
+		// GEO fields cannot be sortable, so NOINDEX is accepted as is, as the server does
+		if f.Type != GeoField {
+			return []error{mismatch}
+		}
+	default:
+		return []error{mismatch}
+	}
+	if noIndex && !sortable {
+		errs = append(errs, errors.New("NOINDEX requires SORTABLE, or the field is neither searchable nor sortable"))
+	}
+	return errs
+}
@@ -0,0 +1,92 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	valid := NewSchema(DefaultOptions).
+		AddField(NewTextField("title")).
+		AddField(NewTextFieldOptions("body", TextFieldOptions{Weight: 2, PhoneticMatcher: "dm:fr"})).
+		AddField(NewTextFieldOptions("name", TextFieldOptions{Sortable: true, NoIndex: true})).
+		AddField(NewSortableNumericField("price")).
+		AddField(NewTagFieldOptions("tags", TagFieldOptions{Separator: ';'})).
+		AddField(NewGeoField("location")).
+		AddField(NewGeoFieldOptions("hidden", GeoFieldOptions{NoIndex: true}))
+	assert.Nil(t, valid.Validate())
+
+	tests := []struct {
+		field Field
+		err   string
+	}{
+		{Field{Name: "title", Type: TextField, Options: NumericFieldOptions{}},
+			"Field title: options of type redisearch.NumericFieldOptions do not apply to TEXT fields"},
+		{Field{Name: "price", Type: NumericField, Options: &NumericFieldOptions{}},
+			"Field price: options of type *redisearch.NumericFieldOptions do not apply to NUMERIC fields"},
+		{Field{Name: "vector", Type: FieldType(42)}, "Field vector: unsupported type 42"},
+		{NewTextFieldOptions("title", TextFieldOptions{Weight: -1}),
+			"Field title: invalid weight -1, must be a positive number"},
+		{NewTextFieldOptions("title", TextFieldOptions{Weight: float32(math.Inf(1))}),
+			"Field title: invalid weight +Inf, must be a positive number"},
+		{NewTextFieldOptions("title", TextFieldOptions{PhoneticMatcher: "dm:de"}),
+			`Field title: unsupported phonetic matcher "dm:de"`},
+		{NewNumericFieldOptions("price", NumericFieldOptions{NoIndex: true}),
+			"Field price: NOINDEX requires SORTABLE, or the field is neither searchable nor sortable"},
+		{NewTagFieldOptions("tags", TagFieldOptions{Separator: ' '}),
+			"Field tags: invalid separator ' ', must be a printable ASCII character"},
+		{NewTagFieldOptions("tags", TagFieldOptions{Separator: 0xe9}),
+			`Field tags: invalid separator 'é', must be a printable ASCII character`},
+		{NewTextField(""), "Invalid field 0: missing name"},
+	}
+	for _, tt := range tests {
+		err := NewSchema(DefaultOptions).AddField(tt.field).Validate()
+		var merr MultiError
+		if assert.True(t, errors.As(err, &merr), tt.err) {
+			assert.Equal(t, 1, len(merr), err.Error())
+			assert.EqualError(t, merr[0], tt.err)
+		}
+	}
+
+	sc := NewSchema(DefaultOptions).
+		AddField(NewTextField("title")).
+		AddField(NewTagField("title")).
+		AddField(NewTextFieldOptions("body", TextFieldOptions{Weight: -2, NoIndex: true}))
+	err := sc.Validate()
+	assert.Equal(t, "[0] Field title: defined twice\n"+
+		"[1] Field body: invalid weight -2, must be a positive number\n"+
+		"[2] Field body: NOINDEX requires SORTABLE, or the field is neither searchable nor sortable\n", err.Error())
+
+	// the limit follows the index options
+	sc = NewSchema(DefaultOptions)
+	for n := 0; n <= maxTextFields; n++ {
+		sc.AddField(NewTextField(fmt.Sprintf("f%d", n)))
+	}
+	assert.EqualError(t, sc.Validate(), "[0] Too many TEXT fields: 33 are indexed, at most 32 can be without MaxTextFields\n")
+	sc.Fields[0] = NewTextFieldOptions("f0", TextFieldOptions{Sortable: true, NoIndex: true})
+	assert.Nil(t, sc.Validate())
+	sc.Fields[0] = NewTextField("f0")
+	sc.Options.NoFieldFlags = true
+	assert.Nil(t, sc.Validate())
+
+	sc = NewSchema(Options{MaxTextFields: true})
+	for n := 0; n <= maxWideTextFields; n++ {
+		sc.AddField(NewTextField(fmt.Sprintf("f%d", n)))
+	}
+	assert.EqualError(t, sc.Validate(), "[0] Too many TEXT fields: 129 are indexed, at most 128 can be\n")
+	sc.Fields = sc.Fields[:maxWideTextFields]
+	args, err := (&Client{name: "idx"}).createArgs(sc)
+	assert.Nil(t, err)
+	assert.Equal(t, "MAXTEXTFIELDS", args[1])
+
+	c := NewClientFromExecutor(FuncExecutor{DoFunc: func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		t.Errorf("Unexpected command %v", args)
+		return "OK", nil
+	}}, "idx")
+	assert.NotNil(t, c.CreateIndex(NewSchema(DefaultOptions).AddField(NewTextField("")).AddField(NewTagField("x"))))
+}
@@ -0,0 +1,206 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// DefaultIteratorPageSize is the number of documents a SearchIterator fetches per request by default
+const DefaultIteratorPageSize = 100
+
+// SearchIterator walks all the documents matching a query, fetching them page by page.
+//
+//	it := c.SearchIterator(q).SetPageSize(500)
+//	for it.Next() {
+//		doc := it.Doc()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+//
+// By default pages are fetched by offset, so documents added or removed during the scan may shift
+// the pages, causing documents to be skipped or returned twice. With UseKeyset, every page starts
+// after the last sort value seen instead, which stays consistent when documents change
+type SearchIterator struct {
+	client   *Client
+	ctx      context.Context
+	q        Query
+	pageSize int
+	keyset   bool
+
+	page    []Document
+	pos     int
+	doc     Document
+	err     error
+	started bool
+	done    bool
+	total   int
+	fetched int
+
+	// keyset state: the last sort value seen, and how many documents with that value were returned
+	boundary    float64
+	hasBoundary bool
+	ties        int
+}
+
+// SearchIterator creates an iterator over all the documents matching q, starting at its offset.
+// The query's Paging.Num is ignored; see SetPageSize
+func (i *Client) SearchIterator(q *Query) *SearchIterator {
+	return i.SearchIteratorContext(context.Background(), q)
+}
+
+// SearchIteratorContext is like SearchIterator, but every page is fetched with the context
+func (i *Client) SearchIteratorContext(ctx context.Context, q *Query) *SearchIterator {
+	return &SearchIterator{
+		client:   i,
+		ctx:      ctx,
+		q:        *q,
+		pageSize: DefaultIteratorPageSize,
+	}
+}
+
+// SetPageSize sets the number of documents fetched per request
+func (it *SearchIterator) SetPageSize(n int) *SearchIterator {
+	if n > 0 {
+		it.pageSize = n
+	}
+	return it
+}
+
+// UseKeyset pages with a range filter on the query's SortBy field instead of offsets, like search_after.
+// The field must be a sortable NUMERIC field, and must be returned in the documents
+func (it *SearchIterator) UseKeyset() *SearchIterator {
+	it.keyset = true
+	return it
+}
+
+// Next advances to the next document, fetching a new page if needed. It returns false when all
+// the documents were returned or an error occurred, which Err tells apart
+func (it *SearchIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.pos >= len(it.page) {
+		if it.done || !it.fetch() {
+			return false
+		}
+	}
+	it.doc = it.page[it.pos]
+	it.pos++
+	return true
+}
+
+// Doc returns the current document
+func (it *SearchIterator) Doc() Document {
+	return it.doc
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *SearchIterator) Err() error {
+	return it.err
+}
+
+// Total returns the number of documents matching the query, as reported with the first page
+func (it *SearchIterator) Total() int {
+	return it.total
+}
+
+// fetch loads the next page, and tells if it has any document
+func (it *SearchIterator) fetch() bool {
+	// keyset pages are filtered, so their end is only told by a short page
+	if !it.keyset && it.started && it.q.Paging.Offset+it.fetched >= it.total {
+		it.done = true
+		return false
+	}
+
+	q := it.q
+	if it.keyset {
+		if err := it.keysetQuery(&q); err != nil {
+			it.err = err
+			return false
+		}
+	} else {
+		q.Paging = Paging{Offset: it.q.Paging.Offset + it.fetched, Num: it.pageSize}
+	}
+
+	res, err := it.client.SearchWithResultContext(it.ctx, &q)
+	if err == nil {
+		err = res.Err()
+	}
+	if err != nil {
+		it.err = err
+		return false
+	}
+	if !it.started {
+		it.started = true
+		it.total = res.Total
+	}
+
+	if it.keyset {
+		if err := it.advanceKeyset(res.Docs); err != nil {
+			it.err = err
+			return false
+		}
+	}
+
+	it.page = res.Docs
+	it.pos = 0
+	it.fetched += len(res.Docs)
+	if len(res.Docs) < it.pageSize {
+		it.done = true
+	}
+	return len(res.Docs) > 0
+}
+
+// keysetQuery restricts the query to the documents sorted after the ones already returned.
+// Documents sharing the boundary value are skipped with the offset
+func (it *SearchIterator) keysetQuery(q *Query) error {
+	if q.SortBy == nil {
+		return errors.New("Keyset pagination requires the query to be sorted")
+	}
+	if q.Flags&QueryNoContent != 0 {
+		return errors.New("Keyset pagination requires the documents content")
+	}
+	if len(q.ReturnFields) > 0 {
+		found := false
+		for _, f := range q.ReturnFields {
+			found = found || f == q.SortBy.Field
+		}
+		if !found {
+			return fmt.Errorf("Keyset pagination requires returning the sort field %s", q.SortBy.Field)
+		}
+	}
+
+	q.Paging = Paging{Offset: it.q.Paging.Offset, Num: it.pageSize}
+	if !it.hasBoundary {
+		return nil
+	}
+	q.Paging.Offset = it.ties
+	filters := make([]Predicate, len(q.Filters), len(q.Filters)+1)
+	copy(filters, q.Filters)
+	if q.SortBy.Ascending {
+		filters = append(filters, GreaterThanEquals(q.SortBy.Field, it.boundary))
+	} else {
+		filters = append(filters, LessThanEquals(q.SortBy.Field, it.boundary))
+	}
+	q.Filters = filters
+	return nil
+}
+
+// advanceKeyset records the last sort value of a page, and how many documents share it
+func (it *SearchIterator) advanceKeyset(docs []Document) error {
+	for _, doc := range docs {
+		v, err := doc.GetFloat(it.q.SortBy.Field)
+		if err != nil {
+			return fmt.Errorf("Document %s: %s", doc.Id, err)
+		}
+		if it.hasBoundary && v == it.boundary {
+			it.ties++
+		} else {
+			it.boundary, it.hasBoundary, it.ties = v, true, 1
+		}
+	}
+	return nil
+}
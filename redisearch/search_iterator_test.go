@@ -0,0 +1,121 @@
+package redisearch
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeIndex answers FT.SEARCH over documents with a single numeric price field, honoring
+// price range filters, SORTBY price and LIMIT
+type fakeIndex struct {
+	prices   map[string]float64
+	requests int
+}
+
+var priceFilter = regexp.MustCompile(`@price:\[(\S+) (\S+)\]`)
+
+func (f *fakeIndex) exec() FuncExecutor {
+	return FuncExecutor{DoFunc: func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		f.requests++
+		min, max := -1e300, 1e300
+		if m := priceFilter.FindStringSubmatch(args[2].(string)); m != nil {
+			if m[1] != negInf {
+				min, _ = strconv.ParseFloat(m[1], 64)
+			}
+			if m[2] != posInf {
+				max, _ = strconv.ParseFloat(m[2], 64)
+			}
+		}
+		var ids []string
+		for id, p := range f.prices {
+			if p >= min && p <= max {
+				ids = append(ids, id)
+			}
+		}
+		asc := true
+		offset, num := 0, 10
+		for i, a := range args {
+			switch a {
+			case "LIMIT":
+				offset, num = args[i+1].(int), args[i+2].(int)
+			case "DESC":
+				asc = false
+			}
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			pi, pj := f.prices[ids[i]], f.prices[ids[j]]
+			if pi != pj {
+				return (pi < pj) == asc
+			}
+			return ids[i] < ids[j]
+		})
+
+		reply := []interface{}{int64(len(ids))}
+		for n := offset; n < len(ids) && n < offset+num; n++ {
+			reply = append(reply, ids[n], []interface{}{"price", fmt.Sprint(f.prices[ids[n]])})
+		}
+		return reply, nil
+	}}
+}
+
+func TestSearchIterator(t *testing.T) {
+	newIndex := func() *fakeIndex {
+		return &fakeIndex{prices: map[string]float64{"a": 1, "b": 2, "c": 2, "d": 2, "e": 3, "f": 4, "g": 5}}
+	}
+	collect := func(it *SearchIterator, during func(n int)) []string {
+		var ids []string
+		for it.Next() {
+			ids = append(ids, it.Doc().Id)
+			if during != nil {
+				during(len(ids))
+			}
+		}
+		assert.Nil(t, it.Err())
+		return ids
+	}
+
+	idx := newIndex()
+	c := NewClientFromExecutor(idx.exec(), "idx")
+	q := NewQuery("*").SetSortBy("price", true)
+	it := c.SearchIterator(q).SetPageSize(3)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e", "f", "g"}, collect(it, nil))
+	assert.Equal(t, 7, it.Total())
+	assert.Equal(t, 3, idx.requests)
+
+	// offsets shift when documents are removed during the scan
+	idx = newIndex()
+	c = NewClientFromExecutor(idx.exec(), "idx")
+	ids := collect(c.SearchIterator(q).SetPageSize(2), func(n int) {
+		if n == 2 {
+			delete(idx.prices, "a")
+		}
+	})
+	assert.NotContains(t, ids, "c")
+
+	// keyset pages do not, and handle ties spanning pages
+	for _, size := range []int{1, 2, 3, 10} {
+		idx = newIndex()
+		c = NewClientFromExecutor(idx.exec(), "idx")
+		ids = collect(c.SearchIterator(q).SetPageSize(size).UseKeyset(), func(n int) {
+			if n == 2 {
+				delete(idx.prices, "a")
+			}
+		})
+		assert.Equal(t, []string{"a", "b", "c", "d", "e", "f", "g"}, ids, "page size %d", size)
+	}
+
+	idx = newIndex()
+	c = NewClientFromExecutor(idx.exec(), "idx")
+	ids = collect(c.SearchIterator(NewQuery("*").SetSortBy("price", false)).SetPageSize(2).UseKeyset(), nil)
+	assert.Equal(t, []string{"g", "f", "e", "b", "c", "d", "a"}, ids)
+
+	it = c.SearchIterator(NewQuery("*")).UseKeyset()
+	assert.False(t, it.Next())
+	assert.NotNil(t, it.Err())
+}
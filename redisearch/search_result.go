@@ -0,0 +1,211 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// SearchResult is a decoded FT.SEARCH reply
+type SearchResult struct {
+	// Total is the total number of documents matching the query, not only the returned ones
+	Total int
+	// Docs are the documents that were decoded, in the order of the reply
+	Docs []Document
+	// Errors has an error for every entry of the reply that could not be decoded, and was left out of Docs
+	Errors []error
+}
+
+// Err returns the decoding errors as a MultiError, or nil if all the entries were decoded
+func (r *SearchResult) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	return MultiError(r.Errors)
+}
+
+// searchEntryLayout tells which elements follow the id of every entry, according to the query flags
+type searchEntryLayout struct {
+	scores   bool
+	payloads bool
+	fields   bool
+}
+
+func newSearchEntryLayout(flags Flag) searchEntryLayout {
+	return searchEntryLayout{
+		scores:   flags&QueryWithScores != 0,
+		payloads: flags&QueryWithPayloads != 0,
+		fields:   flags&QueryNoContent == 0,
+	}
+}
+
+// size is the number of reply elements of an entry
+func (l searchEntryLayout) size() int {
+	n := 1
+	for _, b := range []bool{l.scores, l.payloads, l.fields} {
+		if b {
+			n++
+		}
+	}
+	return n
+}
+
+// decodeSearchReply decodes an FT.SEARCH reply for a query with the given flags, converting the
+// documents to typed values if schema is not nil. An error is only returned if the reply is not
+// a search reply at all; malformed entries are reported in the result
+func decodeSearchReply(reply interface{}, flags Flag, schema *Schema) (*SearchResult, error) {
+	res, ok := reply.([]interface{})
+	if !ok || len(res) == 0 {
+		return nil, fmt.Errorf("Invalid search reply: %v", reply)
+	}
+	total, err := redis.Int(res[0], nil)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid search reply total: %s", err)
+	}
+
+	layout := newSearchEntryLayout(flags)
+	size := layout.size()
+	entries := res[1:]
+	ret := &SearchResult{
+		Total: total,
+		Docs:  make([]Document, 0, len(entries)/size),
+	}
+	for n := 0; n < len(entries); n += size {
+		if n+size > len(entries) {
+			ret.Errors = append(ret.Errors, fmt.Errorf("Truncated search reply entry %d: %d elements, expected %d",
+				n/size, len(entries)-n, size))
+			break
+		}
+		doc, err := decodeSearchEntry(entries[n:n+size], layout)
+		if err == nil && schema != nil {
+			err = schema.ConvertDocument(&doc)
+		}
+		if err != nil {
+			if doc.Id != "" {
+				err = &DocumentError{Id: doc.Id, Err: err}
+			} else {
+				err = fmt.Errorf("Search reply entry %d: %s", n/size, err)
+			}
+			ret.Errors = append(ret.Errors, err)
+			continue
+		}
+		ret.Docs = append(ret.Docs, doc)
+	}
+	return ret, nil
+}
+
+// decodeSearchEntry decodes the elements of a single entry. If the id could be decoded,
+// the returned document has it even when an error is returned
+func decodeSearchEntry(entry []interface{}, layout searchEntryLayout) (doc Document, err error) {
+	id, err := replyString(entry[0])
+	if err != nil {
+		return doc, fmt.Errorf("Invalid id: %s", err)
+	}
+	if id == "" {
+		return doc, errors.New("Empty id")
+	}
+	doc = NewDocument(id, 1)
+	idx := 1
+
+	if layout.scores {
+		s, err := replyString(entry[idx])
+		if err != nil {
+			return doc, fmt.Errorf("Invalid score: %s", err)
+		}
+		score, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return doc, fmt.Errorf("Could not parse score: %s", err)
+		}
+		doc.Score = float32(score)
+		idx++
+	}
+
+	if layout.payloads {
+		switch p := entry[idx].(type) {
+		case nil:
+		case []byte:
+			doc.Payload = p
+		case string:
+			doc.Payload = []byte(p)
+		default:
+			return doc, fmt.Errorf("Invalid payload type %T", p)
+		}
+		idx++
+	}
+
+	if layout.fields {
+		if err = decodeSearchFields(&doc, entry[idx]); err != nil {
+			return doc, err
+		}
+	}
+	return doc, nil
+}
+
+// decodeSearchFields sets the document properties from a flat name/value list
+func decodeSearchFields(doc *Document, v interface{}) error {
+	if v == nil {
+		// documents without any loaded field have no list
+		return nil
+	}
+	lst, ok := v.([]interface{})
+	if !ok {
+		return fmt.Errorf("Invalid fields type %T", v)
+	}
+	if len(lst)%2 != 0 {
+		return fmt.Errorf("Odd number of field elements: %d", len(lst))
+	}
+	for i := 0; i < len(lst); i += 2 {
+		name, err := replyString(lst[i])
+		if err != nil {
+			return fmt.Errorf("Invalid field name: %s", err)
+		}
+		switch val := lst[i+1].(type) {
+		case nil:
+			*doc = doc.Set(name, nil)
+		case []byte, string, int64, []interface{}:
+			*doc = doc.Set(name, convertValue(val))
+		default:
+			return fmt.Errorf("Field %s: invalid value type %T", name, val)
+		}
+	}
+	return nil
+}
+
+// replyString converts a bulk or simple string reply element to a string
+func replyString(v interface{}) (string, error) {
+	switch s := v.(type) {
+	case []byte:
+		return string(s), nil
+	case string:
+		return s, nil
+	default:
+		return "", fmt.Errorf("Expected a string, got %T", v)
+	}
+}
+
+// SearchWithResult searches the index for the given query, and returns the decoded documents
+// along with an error for every entry of the reply that could not be decoded
+func (i *Client) SearchWithResult(q *Query) (*SearchResult, error) {
+	return i.SearchWithResultContext(context.Background(), q)
+}
+
+// SearchWithResultContext is like SearchWithResult, but honors the context's cancellation and deadline
+func (i *Client) SearchWithResultContext(ctx context.Context, q *Query) (*SearchResult, error) {
+	conn, err := getConn(ctx, i.pool)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	args := redis.Args{i.name}
+	args = append(args, q.serialize()...)
+
+	reply, err := conn.Do("FT.SEARCH", args...)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSearchReply(reply, q.Flags, i.typedSchema())
+}
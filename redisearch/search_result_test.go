@@ -0,0 +1,72 @@
+package redisearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeSearchReply(t *testing.T) {
+	b := func(s string) []byte { return []byte(s) }
+	tests := []struct {
+		name   string
+		reply  interface{}
+		flags  Flag
+		ids    []string
+		errors int
+		fail   bool
+	}{
+		{"not an array", b("OK"), 0, nil, 0, true},
+		{"empty", []interface{}{}, 0, nil, 0, true},
+		{"invalid total", []interface{}{b("x")}, 0, nil, 0, true},
+		{"no results", []interface{}{int64(0)}, 0, []string{}, 0, false},
+		{"fields", []interface{}{int64(2),
+			b("doc1"), []interface{}{b("title"), b("hello")},
+			b("doc2"), nil}, 0, []string{"doc1", "doc2"}, 0, false},
+		{"no content", []interface{}{int64(2), b("doc1"), b("doc2")}, QueryNoContent, []string{"doc1", "doc2"}, 0, false},
+		{"scores and payloads", []interface{}{int64(1), b("doc1"), b("0.5"), b("payload"), []interface{}{}},
+			QueryWithScores | QueryWithPayloads, []string{"doc1"}, 0, false},
+		{"bad score", []interface{}{int64(2), b("doc1"), b("x"), b("doc2"), b("1")},
+			QueryWithScores | QueryNoContent, []string{"doc2"}, 1, false},
+		{"bad id", []interface{}{int64(2), int64(1), []interface{}{}, b("doc2"), []interface{}{}}, 0, []string{"doc2"}, 1, false},
+		{"odd fields", []interface{}{int64(1), b("doc1"), []interface{}{b("title")}}, 0, []string{}, 1, false},
+		{"bad fields", []interface{}{int64(1), b("doc1"), b("title")}, 0, []string{}, 1, false},
+		{"bad field value", []interface{}{int64(1), b("doc1"), []interface{}{b("title"), 1.5}}, 0, []string{}, 1, false},
+		{"truncated", []interface{}{int64(2), b("doc1"), b("1"), b("doc2")}, QueryWithScores | QueryNoContent,
+			[]string{"doc1"}, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := decodeSearchReply(tt.reply, tt.flags, nil)
+			if tt.fail {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			ids := []string{}
+			for _, d := range res.Docs {
+				ids = append(ids, d.Id)
+			}
+			assert.Equal(t, tt.ids, ids)
+			assert.Equal(t, tt.errors, len(res.Errors))
+			if tt.errors == 0 {
+				assert.Nil(t, res.Err())
+			} else {
+				assert.NotNil(t, res.Err())
+			}
+		})
+	}
+
+	res, err := decodeSearchReply([]interface{}{int64(3), []byte("doc1"), []byte("0.5"), []byte("payload"),
+		[]interface{}{[]byte("title"), []byte("hello"), []byte("tags"), []interface{}{[]byte("a")}}},
+		QueryWithScores|QueryWithPayloads, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, res.Total)
+	assert.Equal(t, float32(0.5), res.Docs[0].Score)
+	assert.Equal(t, []byte("payload"), res.Docs[0].Payload)
+	assert.Equal(t, "hello", res.Docs[0].Properties["title"])
+	assert.Equal(t, []interface{}{"a"}, res.Docs[0].Properties["tags"])
+
+	res, _ = decodeSearchReply([]interface{}{int64(1), []byte("doc1"), []byte("x")}, QueryWithScores|QueryNoContent, nil)
+	assert.Equal(t, []string{"doc1"}, MultiError(res.Errors).FailedIDs())
+}
@@ -0,0 +1,286 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// SentinelOptions configure the discovery of a master and its replicas through redis sentinels
+type SentinelOptions struct {
+	// MasterName is the name the sentinels monitor the master under
+	MasterName string
+	// Sentinels are the addresses of the sentinels. They are asked in turn, starting with the last one that answered
+	Sentinels []string
+	// SentinelPassword authenticates with the sentinels, if set
+	SentinelPassword string
+	// Client are the options used to dial and pool the connections to the master and replicas.
+	// The timeouts and the TLS configuration also apply to the sentinels
+	Client ClientOptions
+}
+
+// SentinelPool is a pool of connections to the current master of a redis deployment monitored by
+// sentinels. The master's address is asked to the sentinels, and the role of every connection is
+// verified when it is borrowed, so that after a failover connections to the demoted master are
+// discarded and new ones are made to the promoted one
+type SentinelPool struct {
+	opts SentinelOptions
+
+	mu        sync.Mutex
+	sentinels []string
+	master    string
+	replicas  []string
+
+	masterPool  *redis.Pool
+	replicaPool *redis.Pool
+}
+
+// NewSentinelPool creates a pool of connections to the master monitored by the sentinels.
+// No connection is made until the pool is used
+func NewSentinelPool(opts SentinelOptions) *SentinelPool {
+	p := &SentinelPool{
+		opts:      opts,
+		sentinels: append([]string(nil), opts.Sentinels...),
+	}
+
+	p.masterPool = opts.Client.newDialPool(p.dialMaster)
+	p.masterPool.TestOnBorrow = func(c redis.Conn, t time.Time) error {
+		return p.checkRole(c, "master")
+	}
+	p.replicaPool = opts.Client.newDialPool(p.dialReplica)
+	p.replicaPool.TestOnBorrow = func(c redis.Conn, t time.Time) error {
+		return p.checkRole(c, "slave", "master")
+	}
+	return p
+}
+
+// NewSentinelClient creates a new client connecting to the index name on the master monitored by
+// the sentinels. If readFromReplicas is set, queries are sent to the master's replicas
+func NewSentinelClient(opts SentinelOptions, name string, readFromReplicas bool) *Client {
+	p := NewSentinelPool(opts)
+	if readFromReplicas {
+		return NewSplitClient(p.Replicas(), p, name)
+	}
+	return NewClientFromPool(p, name)
+}
+
+// Get gets a connection to the current master
+func (p *SentinelPool) Get() redis.Conn {
+	return p.masterPool.Get()
+}
+
+// GetContext gets a connection to the current master, waiting until the context is done at most
+func (p *SentinelPool) GetContext(ctx context.Context) (redis.Conn, error) {
+	return p.masterPool.GetContext(ctx)
+}
+
+func (p *SentinelPool) Close() error {
+	p.replicaPool.Close()
+	return p.masterPool.Close()
+}
+
+// Replicas returns a pool of connections to random replicas of the master, for read only commands.
+// Connections are made to the master when no replica is available. Closing it leaves the master's pool open
+func (p *SentinelPool) Replicas() ConnPool {
+	return &singleHostPool{p.replicaPool}
+}
+
+// MasterAddr returns the address of the current master, asking the sentinels if it is not known
+func (p *SentinelPool) MasterAddr() (string, error) {
+	p.mu.Lock()
+	addr := p.master
+	p.mu.Unlock()
+	if addr != "" {
+		return addr, nil
+	}
+
+	err := p.querySentinels(func(conn redis.Conn) error {
+		reply, err := redis.Strings(conn.Do("SENTINEL", "get-master-addr-by-name", p.opts.MasterName))
+		if err == redis.ErrNil {
+			return fmt.Errorf("Unknown master %s", p.opts.MasterName)
+		}
+		if err != nil {
+			return err
+		}
+		if len(reply) != 2 {
+			return fmt.Errorf("Invalid master address: %v", reply)
+		}
+		addr = net.JoinHostPort(reply[0], reply[1])
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	p.master = addr
+	p.mu.Unlock()
+	return addr, nil
+}
+
+// replicaAddrs returns the addresses of the master's healthy replicas, asking the sentinels if they are not known
+func (p *SentinelPool) replicaAddrs() ([]string, error) {
+	p.mu.Lock()
+	addrs := p.replicas
+	p.mu.Unlock()
+	if addrs != nil {
+		return addrs, nil
+	}
+
+	err := p.querySentinels(func(conn redis.Conn) error {
+		reply, err := redis.Values(conn.Do("SENTINEL", "slaves", p.opts.MasterName))
+		if err != nil {
+			return err
+		}
+		addrs = make([]string, 0, len(reply))
+		for _, r := range reply {
+			info, err := redis.StringMap(r, nil)
+			if err != nil {
+				return err
+			}
+			if isDownReplica(info["flags"]) {
+				continue
+			}
+			addrs = append(addrs, net.JoinHostPort(info["ip"], info["port"]))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.replicas = addrs
+	p.mu.Unlock()
+	return addrs, nil
+}
+
+// isDownReplica tells if the flags reported by a sentinel for a replica mark it as unusable
+func isDownReplica(flags string) bool {
+	for _, flag := range strings.Split(flags, ",") {
+		switch flag {
+		case "s_down", "o_down", "disconnected":
+			return true
+		}
+	}
+	return false
+}
+
+// forget drops the known master and replicas, so that they are asked to the sentinels again
+func (p *SentinelPool) forget() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.master = ""
+	p.replicas = nil
+}
+
+// querySentinels calls f with a connection to each sentinel in turn, until it succeeds.
+// The sentinel that answered is asked first the next time
+func (p *SentinelPool) querySentinels(f func(conn redis.Conn) error) error {
+	p.mu.Lock()
+	sentinels := append([]string(nil), p.sentinels...)
+	p.mu.Unlock()
+
+	sentinelOpts := ClientOptions{
+		ConnectTimeout: p.opts.Client.ConnectTimeout,
+		ReadTimeout:    p.opts.Client.ReadTimeout,
+		WriteTimeout:   p.opts.Client.WriteTimeout,
+		Password:       p.opts.SentinelPassword,
+		TLSConfig:      p.opts.Client.TLSConfig,
+	}
+
+	var lastErr error = ErrNoHosts
+	for i, addr := range sentinels {
+		conn, err := sentinelOpts.dialFunc(addr)()
+		if err == nil {
+			err = f(conn)
+			conn.Close()
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if i > 0 {
+			p.mu.Lock()
+			for j, s := range p.sentinels {
+				if s == addr {
+					p.sentinels[0], p.sentinels[j] = p.sentinels[j], p.sentinels[0]
+					break
+				}
+			}
+			p.mu.Unlock()
+		}
+		return nil
+	}
+	return lastErr
+}
+
+// dialMaster connects to the current master. If the known master cannot be reached or was
+// demoted, the sentinels are asked for the new one
+func (p *SentinelPool) dialMaster() (redis.Conn, error) {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var addr string
+		if addr, err = p.MasterAddr(); err != nil {
+			return nil, err
+		}
+		var conn redis.Conn
+		if conn, err = p.opts.Client.dialFunc(addr)(); err == nil {
+			if err = p.checkRole(conn, "master"); err == nil {
+				return conn, nil
+			}
+			conn.Close()
+		}
+		p.forget()
+	}
+	return nil, err
+}
+
+// dialReplica connects to a random healthy replica, or to the master if there is none
+func (p *SentinelPool) dialReplica() (redis.Conn, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		addrs, err := p.replicaAddrs()
+		if err != nil || len(addrs) == 0 {
+			break
+		}
+		conn, err := p.opts.Client.dialFunc(addrs[rand.Intn(len(addrs))])()
+		if err == nil {
+			if err = p.checkRole(conn, "slave"); err == nil {
+				return conn, nil
+			}
+			conn.Close()
+		}
+		p.forget()
+	}
+	return p.dialMaster()
+}
+
+// checkRole verifies that the connection is to a server with one of the given roles.
+// A mismatch means a failover happened, so the known topology is dropped
+func (p *SentinelPool) checkRole(c redis.Conn, roles ...string) error {
+	reply, err := redis.Values(c.Do("ROLE"))
+	if err != nil {
+		return err
+	}
+	if len(reply) == 0 {
+		return errors.New("Empty ROLE reply")
+	}
+	role, err := redis.String(reply[0], nil)
+	if err != nil {
+		return err
+	}
+	for _, r := range roles {
+		if role == r {
+			return nil
+		}
+	}
+	p.forget()
+	return fmt.Errorf("Unexpected role %s, expected %s", role, strings.Join(roles, " or "))
+}
@@ -0,0 +1,109 @@
+package redisearch
+
+import (
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+// respArray encodes a RESP array of bulk strings
+func respArray(items ...string) string {
+	s := fmt.Sprintf("*%d\r\n", len(items))
+	for _, item := range items {
+		s += fmt.Sprintf("$%d\r\n%s\r\n", len(item), item)
+	}
+	return s
+}
+
+func TestIsDownReplica(t *testing.T) {
+	tests := []struct {
+		flags string
+		want  bool
+	}{
+		{"slave", false},
+		{"slave,s_down", true},
+		{"slave,o_down,disconnected", true},
+		{"slave,disconnected", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, isDownReplica(tt.flags), tt.flags)
+	}
+}
+
+func TestSentinelPoolFailover(t *testing.T) {
+	listen := func() net.Listener {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Skip("cannot listen: ", err)
+		}
+		return l
+	}
+	sentinel, m1, m2 := listen(), listen(), listen()
+	defer sentinel.Close()
+	defer m1.Close()
+	defer m2.Close()
+
+	// m1 is the master until the failover promotes m2, which is m1's replica before that
+	var failedOver int32
+	master := func() net.Listener {
+		if atomic.LoadInt32(&failedOver) == 1 {
+			return m2
+		}
+		return m1
+	}
+	go serveReplies(sentinel, func(args, prev []string) string {
+		if args[1] == "slaves" {
+			host, port, _ := net.SplitHostPort(m2.Addr().String())
+			down, _, _ := net.SplitHostPort(m1.Addr().String())
+			return "*2\r\n" + respArray("ip", host, "port", port, "flags", "slave") +
+				respArray("ip", down, "port", "1", "flags", "slave,s_down")
+		}
+		host, port, _ := net.SplitHostPort(master().Addr().String())
+		return respArray(host, port)
+	})
+	serveNode := func(l net.Listener, name string) {
+		serveReplies(l, func(args, prev []string) string {
+			if args[0] == "ROLE" {
+				if master() == l {
+					return respArray("master")
+				}
+				return respArray("slave")
+			}
+			return respArray(name)[4:]
+		})
+	}
+	go serveNode(m1, "m1")
+	go serveNode(m2, "m2")
+
+	p := NewSentinelPool(SentinelOptions{
+		MasterName: "mymaster",
+		Sentinels:  []string{"127.0.0.1:1", sentinel.Addr().String()},
+	})
+	defer p.Close()
+
+	get := func(pool ConnPool) string {
+		conn := pool.Get()
+		defer conn.Close()
+		v, err := redis.String(conn.Do("GET", "foo"))
+		assert.Nil(t, err)
+		return v
+	}
+	assert.Equal(t, "m1", get(p))
+	addr, err := p.MasterAddr()
+	assert.Nil(t, err)
+	assert.Equal(t, m1.Addr().String(), addr)
+	assert.Equal(t, sentinel.Addr().String(), p.sentinels[0])
+
+	assert.Equal(t, "m2", get(p.Replicas()))
+
+	// the idle connection to m1 fails the role check, and is replaced by one to m2
+	atomic.StoreInt32(&failedOver, 1)
+	assert.Equal(t, "m2", get(p))
+	addr, err = p.MasterAddr()
+	assert.Nil(t, err)
+	assert.Equal(t, m2.Addr().String(), addr)
+}
@@ -0,0 +1,232 @@
+package redisearch
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoProperty is returned by the typed getters of a Document when the property is not set
+var ErrNoProperty = errors.New("No such property")
+
+// GeoPoint is the decoded value of a GEO field
+type GeoPoint struct {
+	Lon float64
+	Lat float64
+}
+
+// String formats the point as "lon,lat", the way GEO fields are indexed
+func (p GeoPoint) String() string {
+	return strconv.FormatFloat(p.Lon, 'f', -1, 64) + "," + strconv.FormatFloat(p.Lat, 'f', -1, 64)
+}
+
+// ParseGeoPoint parses a point formatted as "lon,lat"
+func ParseGeoPoint(s string) (GeoPoint, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return GeoPoint{}, fmt.Errorf("Invalid geo point %q", s)
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return GeoPoint{}, fmt.Errorf("Invalid geo point longitude: %s", err)
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return GeoPoint{}, fmt.Errorf("Invalid geo point latitude: %s", err)
+	}
+	return GeoPoint{Lon: lon, Lat: lat}, nil
+}
+
+// parseNumeric parses the value of a NUMERIC field as an int64 if it is integral, or a float64
+func parseNumeric(s string) (interface{}, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// splitTags splits the value of a TAG field, dropping empty tags
+func splitTags(s, sep string) []string {
+	tags := []string{}
+	for _, t := range strings.Split(s, sep) {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// ConvertDocument converts the string properties of a returned document to the types of the schema's
+// fields: int64 or float64 for NUMERIC fields, []string split on the separator for TAG fields, and
+// GeoPoint for GEO fields. TEXT fields, and properties that are not in the schema, are left as is
+func (m *Schema) ConvertDocument(doc *Document) error {
+	for _, f := range m.Fields {
+		v, found := doc.Properties[f.Name]
+		if !found {
+			continue
+		}
+		var s string
+		switch val := v.(type) {
+		case string:
+			s = val
+		case []byte:
+			s = string(val)
+		default:
+			// already typed
+			continue
+		}
+
+		var converted interface{}
+		var err error
+		switch f.Type {
+		case NumericField:
+			converted, err = parseNumeric(s)
+		case TagField:
+			converted = splitTags(s, tagSeparator(f))
+		case GeoField:
+			converted, err = ParseGeoPoint(s)
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("Field %s: %s", f.Name, err)
+		}
+		doc.Properties[f.Name] = converted
+	}
+	return nil
+}
+
+// SetSchema sets the schema used to convert the properties of the documents returned by Search
+// to typed values, see Schema.ConvertDocument. A nil schema disables the conversion
+func (i *Client) SetSchema(s *Schema) {
+	i.schema.Store(schemaRef{s})
+}
+
+// LoadSchema reads the index schema with Info, and uses it to convert the properties of the
+// documents returned by Search to typed values
+func (i *Client) LoadSchema() error {
+	info, err := i.Info()
+	if err != nil {
+		return err
+	}
+	i.SetSchema(&info.Schema)
+	return nil
+}
+
+// schemaRef wraps the schema stored in an atomic.Value, which cannot hold a nil interface
+type schemaRef struct {
+	schema *Schema
+}
+
+// typedSchema returns the schema used for typed values, or nil
+func (i *Client) typedSchema() *Schema {
+	ref, _ := i.schema.Load().(schemaRef)
+	return ref.schema
+}
+
+// GetString returns a property as a string. Typed values are formatted
+func (d Document) GetString(name string) (string, error) {
+	v, found := d.Properties[name]
+	if !found || v == nil {
+		return "", fmt.Errorf("Property %s: %w", name, ErrNoProperty)
+	}
+	switch val := v.(type) {
+	case string:
+		return val, nil
+	case []byte:
+		return string(val), nil
+	case []string:
+		return strings.Join(val, ","), nil
+	default:
+		return fmt.Sprint(val), nil
+	}
+}
+
+// GetFloat returns a numeric property as a float64
+func (d Document) GetFloat(name string) (float64, error) {
+	v, found := d.Properties[name]
+	if !found || v == nil {
+		return 0, fmt.Errorf("Property %s: %w", name, ErrNoProperty)
+	}
+	switch val := v.(type) {
+	case float64:
+		return val, nil
+	case float32:
+		return float64(val), nil
+	case int64:
+		return float64(val), nil
+	case int:
+		return float64(val), nil
+	}
+	s, _ := d.GetString(name)
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Property %s: %s", name, err)
+	}
+	return f, nil
+}
+
+// GetInt returns a numeric property as an int64. Fractional values are truncated
+func (d Document) GetInt(name string) (int64, error) {
+	v, found := d.Properties[name]
+	if !found || v == nil {
+		return 0, fmt.Errorf("Property %s: %w", name, ErrNoProperty)
+	}
+	switch val := v.(type) {
+	case int64:
+		return val, nil
+	case int:
+		return int64(val), nil
+	}
+	if s, ok := v.(string); ok {
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n, nil
+		}
+	}
+	f, err := d.GetFloat(name)
+	return int64(f), err
+}
+
+// GetTags returns a tag property as a list of tags. Untyped values are split on commas
+func (d Document) GetTags(name string) ([]string, error) {
+	v, found := d.Properties[name]
+	if !found || v == nil {
+		return nil, fmt.Errorf("Property %s: %w", name, ErrNoProperty)
+	}
+	switch val := v.(type) {
+	case []string:
+		return val, nil
+	case []interface{}:
+		tags := make([]string, len(val))
+		for ii, t := range val {
+			tags[ii] = fmt.Sprint(t)
+		}
+		return tags, nil
+	}
+	s, _ := d.GetString(name)
+	return splitTags(s, ","), nil
+}
+
+// GetTime returns a property holding a unix timestamp in seconds, as stored by DocumentFromStruct,
+// or an RFC 3339 formatted time, as a time.Time
+func (d Document) GetTime(name string) (time.Time, error) {
+	v, found := d.Properties[name]
+	if !found || v == nil {
+		return time.Time{}, fmt.Errorf("Property %s: %w", name, ErrNoProperty)
+	}
+	if t, ok := v.(time.Time); ok {
+		return t, nil
+	}
+	if s, err := d.GetString(name); err == nil {
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return t, nil
+		}
+	}
+	secs, err := d.GetFloat(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, int64(secs*float64(time.Second))), nil
+}
@@ -0,0 +1,93 @@
+package redisearch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertDocument(t *testing.T) {
+	sc := NewSchema(DefaultOptions).
+		AddField(NewTextField("title")).
+		AddField(NewNumericField("count")).
+		AddField(NewNumericField("price")).
+		AddField(NewTagFieldOptions("tags", TagFieldOptions{Separator: ';'})).
+		AddField(NewGeoField("location"))
+
+	doc := NewDocument("doc1", 1).
+		Set("title", "42").
+		Set("count", "42").
+		Set("price", []byte("9.5")).
+		Set("tags", "a; b;;c").
+		Set("location", "-122.41,37.77").
+		Set("other", "1")
+	assert.Nil(t, sc.ConvertDocument(&doc))
+	assert.Equal(t, "42", doc.Properties["title"])
+	assert.Equal(t, int64(42), doc.Properties["count"])
+	assert.Equal(t, 9.5, doc.Properties["price"])
+	assert.Equal(t, []string{"a", "b", "c"}, doc.Properties["tags"])
+	assert.Equal(t, GeoPoint{Lon: -122.41, Lat: 37.77}, doc.Properties["location"])
+	assert.Equal(t, "1", doc.Properties["other"])
+
+	// converting again leaves typed values alone
+	assert.Nil(t, sc.ConvertDocument(&doc))
+	assert.Equal(t, int64(42), doc.Properties["count"])
+
+	bad := NewDocument("doc2", 1).Set("location", "nowhere")
+	assert.NotNil(t, sc.ConvertDocument(&bad))
+
+	res, err := decodeSearchReply([]interface{}{int64(2),
+		[]byte("doc1"), []interface{}{[]byte("count"), []byte("1")},
+		[]byte("doc2"), []interface{}{[]byte("count"), []byte("x")}}, 0, sc)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(res.Docs))
+	assert.Equal(t, int64(1), res.Docs[0].Properties["count"])
+	assert.Equal(t, []string{"doc2"}, MultiError(res.Errors).FailedIDs())
+}
+
+func TestDocumentGetters(t *testing.T) {
+	when := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	doc := NewDocument("doc1", 1).
+		Set("s", "hello").
+		Set("n", "12").
+		Set("f", "1.5").
+		Set("i", int64(7)).
+		Set("tags", "a,b").
+		Set("typed_tags", []string{"x"}).
+		Set("unix", "1588334400").
+		Set("rfc", when.Format(time.RFC3339)).
+		Set("geo", GeoPoint{Lon: 1, Lat: 2})
+
+	s, err := doc.GetString("s")
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", s)
+	s, _ = doc.GetString("geo")
+	assert.Equal(t, "1,2", s)
+
+	n, err := doc.GetInt("n")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(12), n)
+	n, _ = doc.GetInt("f")
+	assert.Equal(t, int64(1), n)
+	f, _ := doc.GetFloat("i")
+	assert.Equal(t, 7.0, f)
+	_, err = doc.GetFloat("s")
+	assert.NotNil(t, err)
+
+	tags, _ := doc.GetTags("tags")
+	assert.Equal(t, []string{"a", "b"}, tags)
+	tags, _ = doc.GetTags("typed_tags")
+	assert.Equal(t, []string{"x"}, tags)
+
+	tm, err := doc.GetTime("unix")
+	assert.Nil(t, err)
+	assert.True(t, when.Equal(tm))
+	tm, err = doc.GetTime("rfc")
+	assert.Nil(t, err)
+	assert.True(t, when.Equal(tm))
+
+	_, err = doc.GetString("missing")
+	assert.True(t, errors.Is(err, ErrNoProperty))
+}